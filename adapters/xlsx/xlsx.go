@@ -0,0 +1,753 @@
+// Package xlsx reads and writes typed rows as Excel workbooks using only the
+// standard library.
+//
+// Cells go through convert's DTO conversion using xlsx, csv, json and convert
+// tags, so the same struct can be used for CSV and XLSX imports:
+//
+//	rows, err := xlsx.ReadXLSX[User](file, xlsx.WithSheet("Users"))
+//	err = xlsx.WriteXLSX(w, rows)
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oarkflow/convert"
+)
+
+var (
+	ErrSheetNotFound = errors.New("xlsx: sheet not found")
+	ErrNoHeader      = errors.New("xlsx: header row not found")
+)
+
+// Options configures ReadXLSX and WriteXLSX.
+type Options struct {
+	Sheet      string // sheet name; the first sheet when empty
+	SheetIndex int    // 0-based sheet position, used when Sheet is empty
+	HeaderRow  int    // 1-based header row; 0 detects the first row naming a field
+	DTOOptions []convert.DTOOption
+	Formats    map[string]string // column name -> Excel number format for WriteXLSX
+	// MaxBytes caps the workbook file and each decompressed part it contains; 0 means
+	// DefaultMaxBytes and a negative value disables the cap.
+	MaxBytes int64
+}
+
+// DefaultMaxBytes is the read limit used when Options.MaxBytes is 0.
+const DefaultMaxBytes = 100 << 20
+
+type Option func(*Options)
+
+func WithSheet(name string) Option { return func(o *Options) { o.Sheet = name } }
+func WithSheetIndex(i int) Option  { return func(o *Options) { o.SheetIndex = i } }
+func WithHeaderRow(n int) Option   { return func(o *Options) { o.HeaderRow = n } }
+func WithMaxBytes(n int64) Option  { return func(o *Options) { o.MaxBytes = n } }
+func WithDTOOptions(opts ...convert.DTOOption) Option {
+	return func(o *Options) { o.DTOOptions = append(o.DTOOptions, opts...) }
+}
+
+// WithColumnFormat overrides the number format WriteXLSX derives from the field type,
+// e.g. WithColumnFormat("price", "#,##0.00").
+func WithColumnFormat(column, format string) Option {
+	return func(o *Options) {
+		if o.Formats == nil {
+			o.Formats = map[string]string{}
+		}
+		o.Formats[column] = format
+	}
+}
+
+func optionsFrom(opts []Option) Options {
+	var o Options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+func (o Options) maxBytes() int64 {
+	switch {
+	case o.MaxBytes == 0:
+		return DefaultMaxBytes
+	case o.MaxBytes < 0:
+		return math.MaxInt64 - 1
+	}
+	return o.MaxBytes
+}
+
+func (o Options) dtoOptions() []convert.DTOOption {
+	return append([]convert.DTOOption{convert.WithDTOTags("xlsx", "csv", "json", "convert")}, o.DTOOptions...)
+}
+
+// Workbook parts.
+type xWorkbook struct {
+	Pr struct {
+		Date1904 bool `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"id,attr"`
+	} `xml:"sheets>sheet"`
+}
+type xRels struct {
+	Rels []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// relTarget returns the part a relationship points at, resolved against the part at base.
+func relTarget(base, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(path.Dir(base), target)
+}
+
+type xText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (x xText) String() string {
+	if len(x.R) == 0 {
+		return x.T
+	}
+	var b strings.Builder
+	for _, r := range x.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xStyles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	Xfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+type xSheet struct {
+	Rows []struct {
+		R int `xml:"r,attr"`
+		C []struct {
+			R  string `xml:"r,attr"`
+			T  string `xml:"t,attr"`
+			S  int    `xml:"s,attr"`
+			V  string `xml:"v"`
+			IS xText  `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type book struct {
+	zr       *zip.Reader
+	max      int64
+	wb       xWorkbook
+	targets  map[string]string
+	strings  []string
+	dates    []bool // by style index: the number format shows a date or time
+	date1904 bool
+}
+
+// openBook reads the package, following _rels/.rels to the workbook and the workbook's
+// relationships to its sheets, shared strings and styles.
+func openBook(r io.Reader, o Options) (*book, error) {
+	max := o.maxBytes()
+	data, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("xlsx: file exceeds %d bytes: %w", max, convert.ErrTooLarge)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: %w", err)
+	}
+	b := &book{zr: zr, max: max, targets: map[string]string{}}
+	wbPath := "xl/workbook.xml"
+	var root xRels
+	if err := b.decode("_rels/.rels", &root); err != nil && !errors.Is(err, errPartNotFound) {
+		return nil, err
+	}
+	for _, r := range root.Rels {
+		if strings.HasSuffix(r.Type, "/officeDocument") {
+			wbPath = relTarget("", r.Target)
+		}
+	}
+	if err := b.decode(wbPath, &b.wb); err != nil {
+		return nil, err
+	}
+	b.date1904 = b.wb.Pr.Date1904
+	var rels xRels
+	if err := b.decode(path.Join(path.Dir(wbPath), "_rels", path.Base(wbPath)+".rels"), &rels); err != nil {
+		return nil, err
+	}
+	sstPath, stylesPath := path.Join(path.Dir(wbPath), "sharedStrings.xml"), path.Join(path.Dir(wbPath), "styles.xml")
+	for _, r := range rels.Rels {
+		t := relTarget(wbPath, r.Target)
+		b.targets[r.ID] = t
+		switch {
+		case strings.HasSuffix(r.Type, "/sharedStrings"):
+			sstPath = t
+		case strings.HasSuffix(r.Type, "/styles"):
+			stylesPath = t
+		}
+	}
+	var sst struct {
+		SI []xText `xml:"si"`
+	}
+	if err := b.decode(sstPath, &sst); err != nil && !errors.Is(err, errPartNotFound) {
+		return nil, err
+	}
+	for _, si := range sst.SI {
+		b.strings = append(b.strings, si.String())
+	}
+	var st xStyles
+	if err := b.decode(stylesPath, &st); err != nil && !errors.Is(err, errPartNotFound) {
+		return nil, err
+	}
+	custom := map[int]string{}
+	for _, f := range st.NumFmts {
+		custom[f.ID] = f.Code
+	}
+	for _, xf := range st.Xfs {
+		code, ok := custom[xf.NumFmtID]
+		b.dates = append(b.dates, ok && isDateFormat(code) || !ok && isDateFormatID(xf.NumFmtID))
+	}
+	return b, nil
+}
+
+var errPartNotFound = errors.New("xlsx: part not found")
+
+func (b *book) decode(name string, v any) error {
+	for _, f := range b.zr.File {
+		if f.Name != name {
+			continue
+		}
+		if f.UncompressedSize64 > uint64(b.max) {
+			return fmt.Errorf("xlsx: %s exceeds %d bytes: %w", name, b.max, convert.ErrTooLarge)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		lr := &io.LimitedReader{R: rc, N: b.max + 1}
+		if err := xml.NewDecoder(lr).Decode(v); err != nil {
+			if lr.N <= 0 {
+				return fmt.Errorf("xlsx: %s exceeds %d bytes: %w", name, b.max, convert.ErrTooLarge)
+			}
+			return fmt.Errorf("xlsx: %s: %w", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errPartNotFound, name)
+}
+
+// SheetNames lists the sheets of a workbook in order.
+func SheetNames(r io.Reader, opts ...Option) ([]string, error) {
+	b, err := openBook(r, optionsFrom(opts))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(b.wb.Sheets))
+	for i, s := range b.wb.Sheets {
+		names[i] = s.Name
+	}
+	return names, nil
+}
+
+// rows returns the selected sheet as rows of typed cells: string, bool, json.Number or
+// time.Time, with nil for blanks. Missing rows are returned empty.
+func (b *book) rows(o Options) ([][]any, error) {
+	idx := o.SheetIndex
+	if o.Sheet != "" {
+		idx = -1
+		for i, s := range b.wb.Sheets {
+			if strings.EqualFold(s.Name, o.Sheet) {
+				idx = i
+			}
+		}
+	}
+	if idx < 0 || idx >= len(b.wb.Sheets) {
+		return nil, fmt.Errorf("%w: %q", ErrSheetNotFound, o.Sheet)
+	}
+	var sh xSheet
+	if err := b.decode(b.targets[b.wb.Sheets[idx].RID], &sh); err != nil {
+		return nil, err
+	}
+	var out [][]any
+	for _, row := range sh.Rows {
+		n := row.R
+		if n <= 0 {
+			n = len(out) + 1
+		}
+		if n > maxRows {
+			return nil, fmt.Errorf("xlsx: row %d: %w", n, convert.ErrInvalid)
+		}
+		for len(out) < n {
+			out = append(out, nil)
+		}
+		var cells []any
+		for _, c := range row.C {
+			col := len(cells)
+			if c.R != "" {
+				col = columnIndex(c.R)
+			}
+			if col < 0 || col >= maxColumns {
+				return nil, fmt.Errorf("xlsx: cell %q: %w", c.R, convert.ErrInvalid)
+			}
+			for len(cells) <= col {
+				cells = append(cells, nil)
+			}
+			v, err := b.cell(c.T, c.S, c.V, c.IS)
+			if err != nil {
+				return nil, fmt.Errorf("xlsx: cell %s: %w", c.R, err)
+			}
+			cells[col] = v
+		}
+		out[n-1] = cells
+	}
+	return out, nil
+}
+
+func (b *book) cell(typ string, style int, v string, is xText) (any, error) {
+	switch typ {
+	case "s":
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 || i >= len(b.strings) {
+			return nil, convert.ErrInvalid
+		}
+		return b.strings[i], nil
+	case "inlineStr":
+		return is.String(), nil
+	case "str", "e":
+		return v, nil
+	case "b":
+		return v == "1", nil
+	case "d":
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return nil, convert.ErrInvalid
+	}
+	if v == "" {
+		return nil, nil
+	}
+	if style >= 0 && style < len(b.dates) && b.dates[style] {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		return fromSerial(f, b.date1904), nil
+	}
+	return json.Number(v), nil
+}
+
+// Sheet limits of the file format; references beyond them are rejected before any
+// row or cell slice grows.
+const (
+	maxRows    = 1 << 20
+	maxColumns = 1 << 14
+)
+
+// columnIndex converts the letters of a cell reference such as "AB12" to a 0-based column,
+// returning -1 when there are none or they pass the last column (XFD).
+func columnIndex(ref string) int {
+	n := 0
+	for _, r := range ref {
+		if r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		if n = n*26 + int(r-'A'+1); n > maxColumns {
+			return -1
+		}
+	}
+	return n - 1
+}
+func columnName(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+func isDateFormatID(id int) bool { return id >= 14 && id <= 22 || id >= 45 && id <= 47 }
+
+// isDateFormat reports whether a custom number format shows a date or time, ignoring
+// quoted text, escaped characters and [colour] sections.
+func isDateFormat(code string) bool {
+	code = strings.ToLower(code)
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; c {
+		case '"':
+			if j := strings.IndexByte(code[i+1:], '"'); j >= 0 {
+				i += j + 1
+			}
+		case '\\', '_', '*':
+			i++
+		case '[':
+			if j := strings.IndexByte(code[i:], ']'); j >= 0 {
+				if s := code[i+1 : i+j]; s == "h" || s == "hh" || s == "m" || s == "mm" || s == "s" || s == "ss" {
+					return true
+				}
+				i += j
+			}
+		case 'y', 'm', 'd', 'h', 's':
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	epoch1900 = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	epoch1904 = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// fromSerial converts an Excel serial date to UTC, rounded to the millisecond.
+func fromSerial(f float64, date1904 bool) time.Time {
+	epoch := epoch1900
+	if date1904 {
+		epoch = epoch1904
+	}
+	return epoch.Add(time.Duration(math.Round(f*86400000)) * time.Millisecond)
+}
+
+// toSerial converts t's wall clock time to an Excel serial date in the 1900 system.
+func toSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return float64(wall.Sub(epoch1900)) / float64(24*time.Hour)
+}
+
+// ReadXLSX converts the rows below the header of one sheet to T. Blank rows are skipped.
+// Without WithHeaderRow, the header is the first row with a cell naming a field of T.
+// Numbers in time columns are read as serial dates, and JSON text in slice, map and
+// struct columns is decoded. Conversion errors carry the data row index, like ReadCSV.
+func ReadXLSX[T any](r io.Reader, opts ...Option) ([]T, error) {
+	o := optionsFrom(opts)
+	b, err := openBook(r, o)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := b.rows(o)
+	if err != nil {
+		return nil, err
+	}
+	dto := o.dtoOptions()
+	known := map[string]bool{}
+	types := map[string]string{}
+	for _, d := range convert.Describe[T](dto...) {
+		for _, n := range append([]string{d.Name}, d.Aliases...) {
+			known[strings.ToLower(n)] = true
+			types[strings.ToLower(n)] = d.Type
+		}
+	}
+	h := o.HeaderRow - 1
+	for i := 0; h < 0 && i < len(rows); i++ {
+		for _, c := range rows[i] {
+			if s, ok := c.(string); ok && known[strings.ToLower(strings.TrimSpace(s))] {
+				h = i
+				break
+			}
+		}
+	}
+	if h < 0 || h >= len(rows) {
+		return nil, ErrNoHeader
+	}
+	headers := make([]string, len(rows[h]))
+	dotted := false
+	for i, c := range rows[h] {
+		headers[i] = strings.TrimSpace(fmt.Sprint(emptyNil(c)))
+		dotted = dotted || strings.Contains(headers[i], ".")
+	}
+	var out []T
+	for _, row := range rows[h+1:] {
+		m := make(map[string]any, len(headers))
+		for i, c := range row {
+			if i >= len(headers) || headers[i] == "" || c == nil {
+				continue
+			}
+			switch x := c.(type) {
+			case json.Number:
+				if f, err := x.Float64(); err == nil && types[strings.ToLower(headers[i])] == "time" {
+					c = fromSerial(f, b.date1904)
+				}
+			case string:
+				if typ := types[strings.ToLower(headers[i])]; (typ == "array" || typ == "object") && json.Valid([]byte(x)) {
+					var v any
+					_ = json.Unmarshal([]byte(x), &v)
+					c = v
+				}
+			}
+			m[headers[i]] = c
+		}
+		if len(m) == 0 {
+			continue
+		}
+		if dotted {
+			m = convert.UnflattenMap(m)
+		}
+		v, err := convert.DTOTo[T](m, dto...)
+		if err != nil {
+			return out, convert.PathError("["+strconv.Itoa(len(out))+"]", convert.KindMap, convert.KindStruct, m, err)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func emptyNil(v any) any {
+	if v == nil {
+		return ""
+	}
+	return v
+}
+
+// Column formats derived from Describe types.
+var typeFormats = map[string]string{"time": "yyyy-mm-dd hh:mm:ss", "integer": "0", "unsigned": "0"}
+
+var builtinFormats = map[string]int{
+	"General": 0, "0": 1, "0.00": 2, "#,##0": 3, "#,##0.00": 4, "0%": 9, "0.00%": 10,
+	"0.00E+00": 11, "mm-dd-yy": 14, "d-mmm-yy": 15, "h:mm": 20, "h:mm:ss": 21, "m/d/yy h:mm": 22, "@": 49,
+}
+
+type column struct {
+	name  string
+	index []int
+	style int
+}
+
+// WriteXLSX writes rows to a single-sheet workbook named by WithSheet, "Sheet1" by default.
+// The header row is bold. Numbers, booleans and times are written as typed cells, with
+// column formats from the Describe types of T or WithColumnFormat; slices, maps and
+// structs are written as JSON text. Writeonly fields are left out.
+func WriteXLSX[T any](w io.Writer, rows []T, opts ...Option) error {
+	o := optionsFrom(opts)
+	sheet := o.Sheet
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+	var cols []column
+	var fmts []string // custom formats, numFmtId 164+
+	var xfs []int     // numFmtId per style index from 2
+	styles := map[string]int{}
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for _, d := range convert.Describe[T](o.dtoOptions()...) {
+		if d.WriteOnly {
+			continue
+		}
+		f, _ := t.FieldByName(d.GoName)
+		c := column{name: d.Name, index: f.Index}
+		format, ok := o.Formats[d.Name]
+		if !ok {
+			format = typeFormats[d.Type]
+		}
+		if format != "" && format != "General" {
+			if s, ok := styles[format]; ok {
+				c.style = s
+			} else {
+				id, ok := builtinFormats[format]
+				if !ok {
+					id = 164 + len(fmts)
+					fmts = append(fmts, format)
+				}
+				c.style = 2 + len(xfs)
+				xfs = append(xfs, id)
+				styles[format] = c.style
+			}
+		}
+		cols = append(cols, c)
+	}
+
+	var sb bytes.Buffer
+	sb.WriteString(xml.Header + `<worksheet xmlns="` + nsMain + `"><sheetData>`)
+	sb.WriteString(`<row r="1">`)
+	for i, c := range cols {
+		writeCell(&sb, i, 1, 1, c.name)
+	}
+	sb.WriteString(`</row>`)
+	for n, row := range rows {
+		rv := reflect.ValueOf(row)
+		for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return convert.PathError("["+strconv.Itoa(n)+"]", convert.KindOfReflect(rv), convert.KindStruct, row, convert.ErrUnsupported)
+		}
+		fmt.Fprintf(&sb, `<row r="%d">`, n+2)
+		for i, c := range cols {
+			fv, err := rv.FieldByIndexErr(c.index)
+			if err != nil {
+				continue
+			}
+			if err := writeCell(&sb, i, n+2, c.style, fv.Interface()); err != nil {
+				return convert.PathError("["+strconv.Itoa(n)+"]."+c.name, convert.KindOf(fv.Interface()), convert.KindString, nil, err)
+			}
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", `<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRel + `"><sheets><sheet name="` + escape(sheet) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+		{"xl/styles.xml", stylesXML(fmts, xfs)},
+		{"xl/worksheets/sheet1.xml", sb.String()},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		body := p.body
+		if !strings.HasPrefix(body, "<?xml") {
+			body = xml.Header + body
+		}
+		if _, err := io.WriteString(f, body); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeCell appends one cell; nil values are left blank.
+func writeCell(b *bytes.Buffer, col, row, style int, v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.IsNil() {
+		return nil
+	}
+	ref := columnName(col) + strconv.Itoa(row)
+	attrs := `<c r="` + ref + `"`
+	if style > 0 {
+		attrs += ` s="` + strconv.Itoa(style) + `"`
+	}
+	num := func(s string) { b.WriteString(attrs + `><v>` + s + `</v></c>`) }
+	if t, ok := rv.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return nil
+		}
+		num(strconv.FormatFloat(toSerial(t), 'f', -1, 64))
+		return nil
+	}
+	if _, ok := rv.Interface().(time.Duration); !ok {
+		switch rv.Kind() {
+		case reflect.Bool:
+			b.WriteString(attrs + ` t="b"><v>` + map[bool]string{true: "1", false: "0"}[rv.Bool()] + `</v></c>`)
+			return nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			num(strconv.FormatInt(rv.Int(), 10))
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			num(strconv.FormatUint(rv.Uint(), 10))
+			return nil
+		case reflect.Float32, reflect.Float64:
+			if f := rv.Float(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+				num(strconv.FormatFloat(f, 'g', -1, 64))
+				return nil
+			}
+		}
+	}
+	var s string
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			s = string(rv.Bytes())
+			break
+		}
+		j, err := json.Marshal(rv.Interface())
+		if err != nil {
+			return err
+		}
+		s = string(j)
+	default:
+		var err error
+		if s, err = convert.ToString(rv.Interface()); err != nil {
+			return err
+		}
+	}
+	b.WriteString(attrs + ` t="inlineStr"><is><t xml:space="preserve">` + escape(s) + `</t></is></c>`)
+	return nil
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func stylesXML(fmts []string, xfs []int) string {
+	var b strings.Builder
+	b.WriteString(`<styleSheet xmlns="` + nsMain + `">`)
+	if len(fmts) > 0 {
+		fmt.Fprintf(&b, `<numFmts count="%d">`, len(fmts))
+		for i, f := range fmts {
+			fmt.Fprintf(&b, `<numFmt numFmtId="%d" formatCode="%s"/>`, 164+i, escape(f))
+		}
+		b.WriteString(`</numFmts>`)
+	}
+	b.WriteString(`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+	fmt.Fprintf(&b, `<cellXfs count="%d"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>`, 2+len(xfs))
+	for _, id := range xfs {
+		fmt.Fprintf(&b, `<xf numFmtId="%d" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>`, id)
+	}
+	b.WriteString(`</cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`)
+	return b.String()
+}
+
+const (
+	nsMain       = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRel        = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	contentTypes = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+	rootRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + nsRel + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	workbookRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + nsRel + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="` + nsRel + `/styles" Target="styles.xml"/></Relationships>`
+)
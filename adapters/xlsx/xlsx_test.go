@@ -0,0 +1,210 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oarkflow/convert"
+)
+
+type address struct {
+	City string `xlsx:"city"`
+}
+
+type order struct {
+	ID      int       `xlsx:"id"`
+	Code    string    `csv:"code"`
+	Total   float64   `xlsx:"total"`
+	Paid    bool      `xlsx:"paid"`
+	Placed  time.Time `xlsx:"placed"`
+	Tags    []string  `xlsx:"tags"`
+	Note    *string   `xlsx:"note"`
+	Address address   `xlsx:"address"`
+	Secret  string    `xlsx:"secret,writeonly"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	placed := time.Date(2024, 3, 15, 13, 45, 30, 0, time.UTC)
+	rows := []order{
+		{ID: 1, Code: "00042", Total: 12.5, Paid: true, Placed: placed, Tags: []string{"a", "b"}, Address: address{City: "Oslo"}, Secret: "x"},
+		{ID: 2, Code: "A<&>", Total: -3},
+	}
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, rows, WithSheet("Orders"), WithColumnFormat("total", "#,##0.00")); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	sheet := zipPart(t, data, "xl/worksheets/sheet1.xml")
+	for _, want := range []string{`<c r="C2" s="3"><v>12.5</v>`, `<c r="D2" t="b"><v>1</v>`, `<c r="E2" s="4"><v>45366.573263888`, `A&lt;&amp;&gt;`} {
+		if !strings.Contains(sheet, want) {
+			t.Fatalf("sheet lacks %s:\n%s", want, sheet)
+		}
+	}
+	if strings.Contains(sheet, "secret") {
+		t.Fatal("writeonly column written")
+	}
+	if styles := zipPart(t, data, "xl/styles.xml"); !strings.Contains(styles, `formatCode="yyyy-mm-dd hh:mm:ss"`) || !strings.Contains(styles, `<xf numFmtId="4"`) {
+		t.Fatalf("styles: %s", styles)
+	}
+	if names, err := SheetNames(bytes.NewReader(data)); err != nil || !reflect.DeepEqual(names, []string{"Orders"}) {
+		t.Fatalf("sheets: %v %v", names, err)
+	}
+	got, err := ReadXLSX[order](bytes.NewReader(data), WithSheet("orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows[0].Secret = ""
+	if !reflect.DeepEqual(got, rows) {
+		t.Fatalf("round trip:\n%+v\n%+v", got, rows)
+	}
+}
+
+func TestReadExcelCells(t *testing.T) {
+	data := workbook(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><workbookPr date1904="1"/>` +
+			`<sheets><sheet name="Notes" sheetId="1" r:id="rId1"/><sheet name="Data" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>Report</t></si><si><t>ID</t></si><si><r><t>Pl</t></r><r><t>aced</t></r></si><si><t>code</t></si></sst>`,
+		"xl/styles.xml":              `<styleSheet><numFmts><numFmt numFmtId="170" formatCode="[$-409]d\-mmm\-yyyy"/><numFmt numFmtId="171" formatCode="&quot;day&quot;0"/></numFmts><cellXfs><xf numFmtId="0"/><xf numFmtId="170"/><xf numFmtId="171"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData/></worksheet>`,
+		"xl/worksheets/data.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c></row>` +
+			`<row r="3"><c r="B3" t="s"><v>1</v></c><c r="C3" t="s"><v>2</v></c><c r="D3" t="s"><v>3</v></c><c r="E3" t="inlineStr"><is><t>Total</t></is></c><c r="F3" t="inlineStr"><is><t>Paid</t></is></c></row>` +
+			`<row r="4"><c r="B4" s="2"><v>7</v></c><c r="C4" s="1"><v>0</v></c><c r="D4"><v>123</v></c><c r="E4"><f>SUM(X1:X2)</f><v>9.75</v></c><c r="F4" t="b"><v>0</v></c></row>` +
+			`<row r="6"><c r="B6" t="str"><f>"8"</f><v>8</v></c><c r="C6" t="d"><v>2024-01-02T03:04:05Z</v></c><c r="F6" t="b"><v>1</v></c></row>` +
+			`</sheetData></worksheet>`,
+	})
+	got, err := ReadXLSX[order](bytes.NewReader(data), WithSheetIndex(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []order{
+		{ID: 7, Code: "123", Total: 9.75, Placed: time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 8, Paid: true, Placed: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v", got)
+	}
+	if _, err := ReadXLSX[order](bytes.NewReader(data), WithSheet("missing")); !errors.Is(err, ErrSheetNotFound) {
+		t.Fatalf("missing sheet: %v", err)
+	}
+	if _, err := ReadXLSX[order](bytes.NewReader(data)); !errors.Is(err, ErrNoHeader) {
+		t.Fatalf("empty sheet: %v", err)
+	}
+	if got, err := ReadXLSX[order](bytes.NewReader(data), WithSheet("Data"), WithHeaderRow(1)); err != nil || len(got) != 0 {
+		t.Fatalf("header row 1: %+v %v", got, err)
+	}
+}
+
+func TestDateFormats(t *testing.T) {
+	for code, want := range map[string]bool{"yyyy-mm-dd": true, "[h]:mm": true, "0.00": false, `"d"0`: false, "[Red]0": false, `0\d`: false, "General": false} {
+		if isDateFormat(code) != want {
+			t.Errorf("%s: want %v", code, want)
+		}
+	}
+	for i, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if columnName(i) != name || columnIndex(name+"9") != i {
+			t.Errorf("column %d: %s", i, columnName(i))
+		}
+	}
+}
+
+func TestPackageRelationships(t *testing.T) {
+	data := workbook(t, map[string]string{
+		"_rels/.rels": `<Relationships><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="data/book.xml"/></Relationships>`,
+		"data/book.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Orders" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"data/_rels/book.xml.rels": `<Relationships><Relationship Id="rId1" Target="sheets/one.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="strings.xml"/></Relationships>`,
+		"data/strings.xml":    `<sst><si><t>id</t></si></sst>`,
+		"data/sheets/one.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>0</v></c></row><row r="2"><c r="A2"><v>5</v></c></row></sheetData></worksheet>`,
+	})
+	got, err := ReadXLSX[order](bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != 5 {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, []order{{ID: 1, Code: strings.Repeat("x", 4096)}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadXLSX[order](bytes.NewReader(buf.Bytes()), WithMaxBytes(64)); !errors.Is(err, convert.ErrTooLarge) {
+		t.Fatalf("input limit: %v", err)
+	}
+	if _, err := ReadXLSX[order](bytes.NewReader(buf.Bytes()), WithMaxBytes(int64(buf.Len()))); !errors.Is(err, convert.ErrTooLarge) {
+		t.Fatalf("part limit: %v", err)
+	}
+	if _, err := SheetNames(bytes.NewReader(buf.Bytes()), WithMaxBytes(-1)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCellReferenceLimits(t *testing.T) {
+	book := func(sheet string) []byte {
+		return workbook(t, map[string]string{
+			"xl/workbook.xml":            `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="S" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+			"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + sheet + `</sheetData></worksheet>`,
+		})
+	}
+	got, err := ReadXLSX[order](bytes.NewReader(book(`<row r="1"><c r="a1" t="inlineStr"><is><t>id</t></is></c></row><row r="2"><c r="a2"><v>3</v></c></row>`)))
+	if err != nil || len(got) != 1 || got[0].ID != 3 {
+		t.Fatalf("lowercase refs: %+v %v", got, err)
+	}
+	for _, sheet := range []string{
+		`<row r="1"><c r="12"><v>1</v></c></row>`,
+		`<row r="1"><c r="ZZZZZZZ1"><v>1</v></c></row>`,
+		`<row r="1"><c r="XFE1"><v>1</v></c></row>`,
+		`<row r="1000000000"><c r="A1000000000"><v>1</v></c></row>`,
+	} {
+		if _, err := ReadXLSX[order](bytes.NewReader(book(sheet))); !errors.Is(err, convert.ErrInvalid) {
+			t.Fatalf("%s: %v", sheet, err)
+		}
+	}
+}
+
+func workbook(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range parts {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipPart(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		if f.Name == name {
+			rc, _ := f.Open()
+			var b bytes.Buffer
+			b.ReadFrom(rc)
+			rc.Close()
+			return b.String()
+		}
+	}
+	t.Fatalf("no part %s", name)
+	return ""
+}
@@ -0,0 +1,421 @@
+package convert
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// requestRoutes are the request parts EncodeRequest routes fields to, in tag priority order.
+var requestRoutes = []string{"path", "query", "header", "cookie", "form"}
+
+// EncodeRequest builds an outgoing request from src, the client-side mirror of BindRequest.
+// Each field goes to the first of its path, query, header, cookie or form tags, or to the
+// parts named by a from= option; other fields form a JSON body. path fields replace {name}
+// in the URL. File fields (io.Reader or *multipart.FileHeader) make the body multipart, and
+// form fields make it url-encoded, with the remaining body fields added as form values.
+// For GET and HEAD requests body fields go to the query string. omitempty skips zero values
+// and readonly fields are never sent.
+func EncodeRequest(method, rawURL string, src any, opts ...DTOOption) (*http.Request, error) {
+	rv := reflect.ValueOf(src)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, ErrNil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, PathError("", KindOf(src), KindStruct, src, ErrUnsupported)
+	}
+	query, header, form := url.Values{}, http.Header{}, url.Values{}
+	params := map[string]string{}
+	var cookies []*http.Cookie
+	body := map[string]any{}
+	files := map[string][]reflect.Value{}
+	bodyInQuery := method == http.MethodGet || method == http.MethodHead
+	for _, f := range dtoMetaFor(rv.Type(), dtoOptionsFrom(opts)).fields {
+		fv := fieldByIndex(rv, f.index)
+		if f.readonly || !fv.IsValid() || !fv.CanInterface() || hasTagOption(f.structField, "omitempty") && fv.IsZero() {
+			continue
+		}
+		route, name := requestRoute(f)
+		if isFileType(fv.Type()) {
+			if !fv.IsZero() {
+				files[name] = append(files[name], fv)
+			}
+			continue
+		}
+		if route == "body" {
+			if !bodyInQuery {
+				body[name] = fv.Interface()
+				continue
+			}
+			if ft := indirectType(fv.Type()); ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+				m, err := StructToFlatMap(fv.Interface(), opts...)
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range m {
+					if v != nil {
+						query.Add(name+"."+k, ToDebugString(v))
+					}
+				}
+				continue
+			}
+		}
+		values, err := fieldStrings(fv, name)
+		if err != nil {
+			return nil, err
+		}
+		switch route {
+		case "path":
+			if len(values) > 0 {
+				params[name] = values[0]
+			}
+		case "header":
+			for _, s := range values {
+				header.Add(name, s)
+			}
+		case "cookie":
+			for _, s := range values {
+				cookies = append(cookies, &http.Cookie{Name: name, Value: s})
+			}
+		case "form":
+			form[name] = append(form[name], values...)
+		default:
+			query[name] = append(query[name], values...)
+		}
+	}
+	for name, v := range params {
+		rawURL = strings.ReplaceAll(rawURL, "{"+name+"}", url.PathEscape(v))
+	}
+	if pathPart, _, _ := strings.Cut(rawURL, "?"); strings.Contains(pathPart, "{") {
+		i := strings.IndexByte(pathPart, '{')
+		return nil, PathError(pathPart[i:], KindInvalid, KindString, nil, errf("%w: unresolved path parameter", ErrEmpty))
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	for k, v := range query {
+		q[k] = append(q[k], v...)
+	}
+	u.RawQuery = q.Encode()
+	var payload []byte
+	contentType := ""
+	switch {
+	case len(files) > 0 || len(form) > 0:
+		for k, v := range body {
+			values, err := fieldStrings(reflect.ValueOf(v), k)
+			if err != nil {
+				return nil, err
+			}
+			form[k] = append(form[k], values...)
+		}
+		if len(files) == 0 {
+			payload, contentType = []byte(form.Encode()), "application/x-www-form-urlencoded"
+			break
+		}
+		if payload, contentType, err = encodeMultipart(form, files); err != nil {
+			return nil, err
+		}
+	case len(body) > 0:
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+		contentType = "application/json"
+	}
+	var rd io.Reader
+	if payload != nil {
+		rd = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, u.String(), rd)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	return req, nil
+}
+
+// requestRoute returns the request part and wire name for f.
+func requestRoute(f dtoFieldMeta) (route, name string) {
+	for _, from := range f.from {
+		if from == "body" || slices.Contains(requestRoutes, from) {
+			if n := tagName(f.structField.Tag.Get(from)); n != "" {
+				return from, n
+			}
+			return from, f.primary
+		}
+	}
+	for _, r := range requestRoutes {
+		if n := tagName(f.structField.Tag.Get(r)); n != "" {
+			return r, n
+		}
+	}
+	if n := tagName(f.structField.Tag.Get("json")); n != "" {
+		return "body", n
+	}
+	return "body", f.primary
+}
+func hasTagOption(f reflect.StructField, option string) bool {
+	for _, tag := range dtoOptionTags {
+		for _, p := range strings.Split(f.Tag.Get(tag), ",")[1:] {
+			if strings.TrimSpace(p) == option {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldStrings renders a scalar, or each element of a slice, as request strings.
+func fieldStrings(v reflect.Value, path string) ([]string, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		out := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s, err := fieldStrings(v.Index(i), indexPath(path, i))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, s...)
+		}
+		return out, nil
+	}
+	if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		if err != nil {
+			return nil, PathError(path, KindOfReflect(v), KindString, v.Interface(), err)
+		}
+		return []string{string(b)}, nil
+	}
+	s, err := ToString(v.Interface())
+	if err != nil {
+		return nil, PathError(path, KindOfReflect(v), KindString, v.Interface(), err)
+	}
+	return []string{s}, nil
+}
+
+var readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+func isFileType(t reflect.Type) bool {
+	return t == fileHeaderType || t == fileHeadersType || t.Kind() == reflect.Interface && t.Implements(readerType) || t.Kind() == reflect.Pointer && t.Implements(readerType)
+}
+
+func encodeMultipart(form url.Values, files map[string][]reflect.Value) ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, vs := range form {
+		for _, v := range vs {
+			if err := w.WriteField(k, v); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	for name, values := range files {
+		for _, fv := range values {
+			if err := writeFileParts(w, name, fv.Interface()); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+func writeFileParts(w *multipart.Writer, name string, v any) error {
+	switch x := v.(type) {
+	case []*multipart.FileHeader:
+		for _, fh := range x {
+			if err := writeFileParts(w, name, fh); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *multipart.FileHeader:
+		if x == nil {
+			return nil
+		}
+		f, err := x.Open()
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return copyFilePart(w, name, x.Filename, f)
+	case io.Reader:
+		filename := name
+		if n, ok := x.(interface{ Name() string }); ok {
+			filename = filepath.Base(n.Name())
+		}
+		return copyFilePart(w, name, filename, x)
+	}
+	return nil
+}
+func copyFilePart(w *multipart.Writer, name, filename string, r io.Reader) error {
+	pw, err := w.CreateFormFile(name, filename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(pw, r)
+	return err
+}
+
+// ErrUnexpectedStatus is wrapped by every ResponseError.
+var ErrUnexpectedStatus = errors.New("convert: unexpected response status")
+
+// ResponseError is returned by BindResponse for non-2xx responses. Body holds the error body
+// decoded into E; Raw keeps the bytes in case E could not be decoded.
+type ResponseError[E any] struct {
+	Status int
+	Header http.Header
+	Body   E
+	Raw    []byte
+}
+
+func (e *ResponseError[E]) Error() string {
+	return ErrUnexpectedStatus.Error() + " " + strconv.Itoa(e.Status) + " " + http.StatusText(e.Status)
+}
+func (e *ResponseError[E]) Unwrap() error { return ErrUnexpectedStatus }
+
+// BindResponse reads and closes resp.Body and binds the response into T. Fields tagged
+// header are read from the response headers, fields tagged trailer from the trailers sent
+// after the body, a field tagged status receives the status code, and a field tagged body
+// receives the whole decoded body; without one, an object body is bound field by field.
+// The body is decoded by Content-Type like BindRequest, and text/* bodies decode to a
+// string. Non-2xx responses bind into E the same way and are returned as a
+// *ResponseError[E]. Bodies over the DefaultHTTPBindOptions cap fail with ErrTooLarge.
+func BindResponse[T, E any](resp *http.Response, opts ...DTOOption) (T, error) {
+	return BindResponseWith[T, E](resp, WithBindDTOOptions(opts...))
+}
+
+// BindResponseWith is BindResponse with options; MaxBodyBytes caps the bytes read from
+// resp.Body and a value <= 0 disables the cap.
+func BindResponseWith[T, E any](resp *http.Response, opts ...HTTPBindOption) (T, error) {
+	var out T
+	o := httpBindOptionsFrom(opts)
+	body := io.Reader(resp.Body)
+	if o.MaxBodyBytes > 0 {
+		body = io.LimitReader(resp.Body, o.MaxBodyBytes+1)
+	}
+	raw, err := io.ReadAll(body)
+	resp.Body.Close()
+	if err != nil {
+		return out, err
+	}
+	if o.MaxBodyBytes > 0 && int64(len(raw)) > o.MaxBodyBytes {
+		return out, errf("%w: response body exceeds %d bytes", ErrTooLarge, o.MaxBodyBytes)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		re := &ResponseError[E]{Status: resp.StatusCode, Header: resp.Header, Raw: raw}
+		_ = bindResponseInto(&re.Body, resp, raw, o.DTOOptions)
+		return out, re
+	}
+	return out, bindResponseInto(&out, resp, raw, o.DTOOptions)
+}
+
+func bindResponseInto(dst any, resp *http.Response, raw []byte, opts []DTOOption) error {
+	all := append([]DTOOption{WithDTOFlatten()}, opts...)
+	opt := dtoOptionsFrom(all)
+	decoded, err := decodeResponseBody(resp.Header.Get("Content-Type"), raw, opt)
+	if err != nil {
+		return err
+	}
+	t := reflect.TypeOf(dst).Elem()
+	if indirectType(t).Kind() != reflect.Struct || indirectType(t) == reflect.TypeOf(time.Time{}) {
+		if decoded == nil {
+			return nil
+		}
+		return DTO(dst, decoded, all...)
+	}
+	meta := dtoMetaFor(indirectType(t), opt)
+	body, _ := decoded.(map[string]any)
+	for _, f := range meta.fields {
+		if _, ok := f.structField.Tag.Lookup("body"); ok {
+			body = nil
+		}
+	}
+	src := mergeBindSources(t, opt, []BindSource{FromMapSource("body", body), FromHeaderSource(resp.Header)})
+	for _, f := range meta.fields {
+		if _, ok := f.structField.Tag.Lookup("status"); ok {
+			src[f.primary] = resp.StatusCode
+		} else if _, ok := f.structField.Tag.Lookup("body"); ok && decoded != nil {
+			src[f.primary] = decoded
+		} else if h, tag, ok := responseHeaderField(resp, f.structField.Tag); ok {
+			// Header and trailer fields only ever come from the response metadata,
+			// never from a body key spelled the same way.
+			delete(src, f.primary)
+			name := tagName(tag)
+			if name == "" {
+				name = f.primary
+			}
+			if vs := h.Values(name); len(vs) == 1 {
+				src[f.primary] = vs[0]
+			} else if len(vs) > 1 {
+				src[f.primary] = vs
+			}
+		}
+	}
+	return DTO(dst, src, all...)
+}
+
+func responseHeaderField(resp *http.Response, tag reflect.StructTag) (http.Header, string, bool) {
+	if name, ok := tag.Lookup("header"); ok {
+		return resp.Header, name, true
+	}
+	if name, ok := tag.Lookup("trailer"); ok {
+		return resp.Trailer, name, true
+	}
+	return nil, "", false
+}
+
+func decodeResponseBody(ct string, raw []byte, opt DTOOptions) (any, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	mt, params := "application/json", map[string]string{}
+	if ct != "" {
+		var err error
+		if mt, params, err = mime.ParseMediaType(ct); err != nil {
+			return nil, errf("%w: %w", ErrUnsupportedMediaType, err)
+		}
+	}
+	dec, ok := bodyDecoderFor(mt, jsonLimitsFrom(HTTPBindOptions{}, opt))
+	if !ok {
+		if strings.HasPrefix(mt, "text/") {
+			return string(raw), nil
+		}
+		return nil, errf("%w: %s", ErrUnsupportedMediaType, mt)
+	}
+	v, err := dec(bytes.NewReader(raw), params)
+	if err != nil {
+		return nil, errf("%w: %w", ErrMalformedBody, err)
+	}
+	return v, nil
+}
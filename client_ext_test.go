@@ -0,0 +1,165 @@
+package convert
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sdkUpdateUser struct {
+	OrgID   int       `json:"org_id" path:"org_id"`
+	ID      int       `json:"id,from=path"`
+	DryRun  bool      `query:"dry_run,omitempty"`
+	Fields  []string  `query:"fields"`
+	TraceID string    `header:"X-Trace-Id"`
+	Session string    `cookie:"session"`
+	Name    string    `json:"name"`
+	Since   time.Time `json:"since"`
+	Created string    `json:"created,readonly"`
+}
+
+func TestEncodeRequestRoundTrip(t *testing.T) {
+	in := sdkUpdateUser{OrgID: 7, ID: 42, Fields: []string{"a", "b"}, TraceID: "t-1", Session: "s", Name: "Ann", Since: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Created: "x"}
+	req, err := EncodeRequest(http.MethodPut, "http://api.test/orgs/{org_id}/users/{id}?v=1", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Path != "/orgs/7/users/42" || req.URL.Query().Get("v") != "1" || strings.Join(req.URL.Query()["fields"], ",") != "a,b" || req.URL.Query().Has("dry_run") {
+		t.Fatalf("url: %s", req.URL)
+	}
+	if req.Header.Get("X-Trace-Id") != "t-1" || req.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("headers: %v", req.Header)
+	}
+	b, _ := io.ReadAll(req.Body)
+	if strings.Contains(string(b), "created") || strings.Contains(string(b), `"id"`) || !strings.Contains(string(b), `"name":"Ann"`) {
+		t.Fatalf("body: %s", b)
+	}
+	req.Body = io.NopCloser(strings.NewReader(string(b)))
+
+	var got sdkUpdateUser
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /orgs/{org_id}/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if err := BindRequest(r, &got); err != nil {
+			t.Fatal(err)
+		}
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+	in.Created = ""
+	if got.OrgID != 7 || got.ID != 42 || got.Name != "Ann" || got.Session != "s" || got.TraceID != "t-1" || !got.Since.Equal(in.Since) || strings.Join(got.Fields, ",") != "a,b" {
+		t.Fatalf("round trip: %+v", got)
+	}
+
+	if _, err := EncodeRequest(http.MethodGet, "http://api.test/users/{missing}", in); err == nil {
+		t.Fatal("expected unresolved path parameter error")
+	}
+	req, err = EncodeRequest(http.MethodGet, "http://api.test/orgs/{org_id}/users/{id}", in)
+	if err != nil || req.Body != nil || req.URL.Query().Get("name") != "Ann" {
+		t.Fatalf("GET puts body fields in the query: %v %v", req.URL, err)
+	}
+}
+
+func TestEncodeRequestMultipart(t *testing.T) {
+	type upload struct {
+		Title  string    `form:"title"`
+		Note   string    `json:"note"`
+		Avatar io.Reader `form:"avatar"`
+	}
+	req, err := EncodeRequest(http.MethodPost, "http://api.test/upload", upload{Title: "hi", Note: "n", Avatar: strings.NewReader("png")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type received struct {
+		Title  string                `form:"title"`
+		Note   string                `form:"note"`
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+	var got received
+	if err := BindMultipart(req, &got, DefaultMultipartLimits()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "hi" || got.Note != "n" || got.Avatar == nil || got.Avatar.Size != 3 {
+		t.Fatalf("multipart: %+v", got)
+	}
+	req, err = EncodeRequest(http.MethodPost, "http://api.test/login", struct {
+		User string `form:"user"`
+	}{"ann"})
+	if err != nil || req.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Fatalf("form: %v", err)
+	}
+}
+
+func TestBindResponse(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	type userResp struct {
+		Status    int    `status:""`
+		Remaining int    `header:"X-RateLimit-Remaining"`
+		ID        int    `json:"id"`
+		Name      string `json:"name"`
+	}
+	type listResp struct {
+		Status int    `status:""`
+		Total  int    `header:"X-Total-Count"`
+		Users  []user `body:""`
+	}
+	type apiError struct {
+		Status  int    `status:""`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "41")
+		w.Header().Set("X-Total-Count", "2")
+		switch r.URL.Path {
+		case "/user":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"id":1,"name":"Ann","X-RateLimit-Remaining":999}`)
+		case "/users":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `[{"id":1},{"id":2}]`)
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = io.WriteString(w, "pong")
+		default:
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"code":"not_found","message":"no such user"}`)
+		}
+	}))
+	defer srv.Close()
+	get := func(path string) *http.Response {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	u, err := BindResponse[userResp, apiError](get("/user"))
+	if err != nil || u.Status != 200 || u.Remaining != 41 || u.ID != 1 || u.Name != "Ann" {
+		t.Fatalf("user: %+v %v", u, err)
+	}
+	l, err := BindResponse[listResp, apiError](get("/users"))
+	if err != nil || l.Total != 2 || len(l.Users) != 2 || l.Users[1].ID != 2 {
+		t.Fatalf("list: %+v %v", l, err)
+	}
+	s, err := BindResponse[string, apiError](get("/text"))
+	if err != nil || s != "pong" {
+		t.Fatalf("text: %q %v", s, err)
+	}
+	if _, err := BindResponseWith[userResp, apiError](get("/user"), WithMaxBodyBytes(8)); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected body cap, got %v", err)
+	}
+	_, err = BindResponse[userResp, apiError](get("/missing"))
+	var re *ResponseError[apiError]
+	if !errors.As(err, &re) || !errors.Is(err, ErrUnexpectedStatus) || re.Status != 404 || re.Body.Status != 404 || re.Body.Code != "not_found" || re.Body.Message != "no such user" {
+		t.Fatalf("error body: %v %+v", err, re)
+	}
+}
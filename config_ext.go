@@ -0,0 +1,1116 @@
+package convert
+
+import (
+	"crypto/sha256"
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrSecretNotFound = errors.New("convert: secret not found")
+
+// redactedValue replaces sensitive values and resolved secrets in reports and logs.
+const redactedValue = "[REDACTED]"
+
+// SecretResolver resolves the reference part of a secret value, e.g. "DB_PASS"
+// for ${env:DB_PASS} or "/run/secrets/db" for file:///run/secrets/db.
+type SecretResolver func(ref string) (string, error)
+
+var secretResolvers sync.Map // map[string]SecretResolver
+
+// RegisterSecretResolver installs a resolver for scheme. Config strings of the form
+// scheme://ref or ${scheme:ref} are resolved when loaded with WithConfigSecrets or WithDTOSecrets.
+// The env and file schemes are built in and can be overridden.
+func RegisterSecretResolver(scheme string, fn SecretResolver) {
+	if scheme == "" || fn == nil {
+		return
+	}
+	secretResolvers.Store(strings.ToLower(scheme), fn)
+}
+func UnregisterSecretResolver(scheme string) { secretResolvers.Delete(strings.ToLower(scheme)) }
+
+func secretResolverFor(scheme string) (SecretResolver, bool) {
+	scheme = strings.ToLower(scheme)
+	if fn, ok := secretResolvers.Load(scheme); ok {
+		return fn.(SecretResolver), true
+	}
+	switch scheme {
+	case "env":
+		return envSecret, true
+	case "file":
+		return fileSecret, true
+	}
+	return nil, false
+}
+func envSecret(ref string) (string, error) {
+	v, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", errf("%w: env %s", ErrSecretNotFound, ref)
+	}
+	return v, nil
+}
+func fileSecret(ref string) (string, error) {
+	b, err := os.ReadFile(ref)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", errf("%w: file %s", ErrSecretNotFound, ref)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// ResolveSecret resolves a scheme://ref value or every ${scheme:ref} reference embedded in s.
+// Strings without a registered scheme are returned unchanged.
+func ResolveSecret(s string) (string, error) {
+	out, _, err := resolveSecretString(s)
+	return out, err
+}
+
+func resolveSecretString(s string) (string, bool, error) {
+	if !strings.Contains(s, "://") && !strings.Contains(s, "${") {
+		return s, false, nil
+	}
+	if scheme, ref, ok := strings.Cut(s, "://"); ok && validSecretScheme(scheme) {
+		if fn, ok := secretResolverFor(scheme); ok {
+			v, err := fn(ref)
+			if err != nil {
+				return "", true, err
+			}
+			return v, true, nil
+		}
+	}
+	var b strings.Builder
+	found := false
+	rest := s
+	for {
+		i := strings.Index(rest, "${")
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(rest[i:], '}')
+		if j < 0 {
+			break
+		}
+		end := i + j + 1
+		scheme, ref, ok := strings.Cut(rest[i+2:i+j], ":")
+		fn, known := secretResolverFor(scheme)
+		if !ok || !known || !validSecretScheme(scheme) || strings.HasPrefix(ref, "-") {
+			b.WriteString(rest[:end])
+			rest = rest[end:]
+			continue
+		}
+		v, err := fn(ref)
+		if err != nil {
+			return "", true, err
+		}
+		b.WriteString(rest[:i])
+		b.WriteString(v)
+		rest = rest[end:]
+		found = true
+	}
+	if !found {
+		return s, false, nil
+	}
+	b.WriteString(rest)
+	return b.String(), true, nil
+}
+func validSecretScheme(s string) bool {
+	if s == "" || !isASCIIAlpha(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if !isASCIIAlpha(c) && !(c >= '0' && c <= '9') && c != '+' && c != '-' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSecretRef reports whether s holds a reference that resolveSecretString would resolve.
+func hasSecretRef(s string) bool {
+	if scheme, _, ok := strings.Cut(s, "://"); ok && validSecretScheme(scheme) {
+		if _, ok := secretResolverFor(scheme); ok {
+			return true
+		}
+	}
+	for rest := s; ; {
+		i := strings.Index(rest, "${")
+		if i < 0 {
+			return false
+		}
+		j := strings.IndexByte(rest[i:], '}')
+		if j < 0 {
+			return false
+		}
+		scheme, ref, ok := strings.Cut(rest[i+2:i+j], ":")
+		if _, known := secretResolverFor(scheme); ok && known && validSecretScheme(scheme) && !strings.HasPrefix(ref, "-") {
+			return true
+		}
+		rest = rest[i+j+1:]
+	}
+}
+
+// resolvedSecrets holds the SHA-256 of every value resolved from a secret reference, so
+// reports redact them wherever the loaded value ends up without keeping the plain text.
+var resolvedSecrets sync.Map // map[[32]byte]struct{}
+
+func rememberSecret(s string) {
+	if s != "" {
+		resolvedSecrets.Store(sha256.Sum256([]byte(s)), struct{}{})
+	}
+}
+func isResolvedSecret(v any) bool {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return false
+	}
+	_, ok = resolvedSecrets.Load(sha256.Sum256([]byte(s)))
+	return ok
+}
+
+// redactValue hides v when it belongs to a sensitive field or was resolved from a secret.
+func redactValue(v any, sensitive bool) any {
+	if (sensitive || isResolvedSecret(v)) && !IsNilLike(v) {
+		return redactedValue
+	}
+	return v
+}
+
+// redactPath reports whether path was listed with WithDTORedactPaths.
+func (o DTOOptions) redactPath(path string) bool {
+	for _, p := range o.RedactPaths {
+		if strings.EqualFold(p, path) {
+			return true
+		}
+	}
+	return false
+}
+func redactErrorValue(err error) error {
+	var d *ErrorDetail
+	if errors.As(err, &d) && d.Value != nil {
+		d.Value = redactedValue
+	}
+	return err
+}
+
+var (
+	ErrReferenceCycle   = errors.New("convert: reference cycle")
+	ErrUnknownReference = errors.New("convert: unknown reference")
+)
+
+// Interpolate expands ${KEY} and ${KEY:-fallback} references between the keys of m.
+// Keys are looked up by exact, case-insensitive and dotted path, then in the process
+// environment. A value written as $(( ... )), such as "$(( ${base_timeout} * 2 ))", is
+// evaluated as a number or duration expression; anything else stays text.
+func Interpolate(m map[string]any) (map[string]any, error) {
+	ip := newInterpolator(m, true)
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		x, err := ip.expandValue(v, k)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = x
+	}
+	return out, nil
+}
+
+type interpolator struct {
+	flat     map[string]any
+	extra    func(name string) (key string, raw any, ok bool)
+	cache    map[string]any
+	visiting map[string]bool
+	env      bool
+}
+
+func newInterpolator(m map[string]any, env bool) *interpolator {
+	return &interpolator{flat: FlattenMap(m), cache: map[string]any{}, visiting: map[string]bool{}, env: env}
+}
+
+// hasInterpolation reports whether s contains a reference or an expression.
+func hasInterpolation(s string) bool {
+	return strings.Contains(s, "${") || isExpression(s)
+}
+
+// isExpression reports whether s uses the explicit $(( ... )) arithmetic syntax.
+func isExpression(s string) bool {
+	s = strings.TrimSpace(s)
+	return len(s) >= 5 && strings.HasPrefix(s, "$((") && strings.HasSuffix(s, "))")
+}
+
+func (ip *interpolator) expandValue(v any, path string) (any, error) {
+	switch x := v.(type) {
+	case string:
+		if !hasInterpolation(x) {
+			return x, nil
+		}
+		if _, ok := ip.flat[path]; ok {
+			return ip.resolveKey(path, x)
+		}
+		return ip.expand(x, path)
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, val := range x {
+			e, err := ip.expandValue(val, joinPath(path, k))
+			if err != nil {
+				return nil, err
+			}
+			out[k] = e
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(x))
+		for i, val := range x {
+			e, err := ip.expandValue(val, indexPath(path, i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = e
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+func (ip *interpolator) resolveKey(key string, raw any) (any, error) {
+	if v, ok := ip.cache[key]; ok {
+		return v, nil
+	}
+	if ip.visiting[key] {
+		return nil, PathError(key, KindString, KindString, raw, errf("%w: %s", ErrReferenceCycle, key))
+	}
+	s, ok := raw.(string)
+	if !ok || !hasInterpolation(s) {
+		return raw, nil
+	}
+	ip.visiting[key] = true
+	v, err := ip.expand(s, key)
+	delete(ip.visiting, key)
+	if err != nil {
+		return nil, err
+	}
+	ip.cache[key] = v
+	return v, nil
+}
+
+func (ip *interpolator) lookup(name string) (any, bool, error) {
+	if raw, ok := ip.flat[name]; ok {
+		v, err := ip.resolveKey(name, raw)
+		return v, true, err
+	}
+	for k, raw := range ip.flat {
+		if strings.EqualFold(k, name) {
+			v, err := ip.resolveKey(k, raw)
+			return v, true, err
+		}
+	}
+	if ip.extra != nil {
+		if key, raw, ok := ip.extra(name); ok {
+			v, err := ip.resolveKey(key, raw)
+			return v, true, err
+		}
+	}
+	if ip.env {
+		if v, ok := os.LookupEnv(name); ok {
+			return v, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (ip *interpolator) expand(s, path string) (any, error) {
+	if !isExpression(s) {
+		return ip.template(s, path)
+	}
+	t := strings.TrimSpace(s)
+	x, err := ip.template(t[3:len(t)-2], path)
+	if err != nil {
+		return nil, err
+	}
+	v, err := evalExpression(ToDebugString(x))
+	if err != nil {
+		return nil, PathError(path, KindString, KindString, s, errf("%w: expression %q", ErrInvalid, t))
+	}
+	return v, nil
+}
+
+// template substitutes references in s; a value that is a single reference keeps its type.
+func (ip *interpolator) template(s, path string) (any, error) {
+	var b strings.Builder
+	literal := false
+	var single any
+	refs := 0
+	escaped := false
+	rest := s
+	for {
+		i := strings.Index(rest, "${")
+		if i < 0 {
+			break
+		}
+		if i > 0 && rest[i-1] == '$' {
+			// $${ is an escaped, literal ${.
+			b.WriteString(rest[:i])
+			b.WriteString("{")
+			literal = true
+			escaped = true
+			rest = rest[i+2:]
+			continue
+		}
+		end := matchingBrace(rest, i+2)
+		if end < 0 {
+			break
+		}
+		inner := rest[i+2 : end]
+		name, fallback, hasFallback := strings.Cut(inner, ":-")
+		if scheme, _, ok := strings.Cut(inner, ":"); ok && !hasFallback {
+			if _, known := secretResolverFor(scheme); known {
+				b.WriteString(rest[:end+1])
+				literal = true
+				rest = rest[end+1:]
+				continue
+			}
+		}
+		v, found, err := ip.lookup(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if hasFallback && (!found || v == nil || v == "") {
+			v, err = ip.expand(fallback, path)
+			if err != nil {
+				return nil, err
+			}
+			found = true
+		}
+		if !found {
+			return nil, PathError(path, KindString, KindString, s, errf("%w: %s", ErrUnknownReference, name))
+		}
+		b.WriteString(rest[:i])
+		literal = literal || i > 0
+		b.WriteString(ToDebugString(v))
+		single = v
+		refs++
+		rest = rest[end+1:]
+	}
+	b.WriteString(rest)
+	if refs == 0 && !escaped {
+		return s, nil
+	}
+	if refs == 1 && !literal && rest == "" {
+		return single, nil
+	}
+	return b.String(), nil
+}
+
+func matchingBrace(s string, from int) int {
+	depth := 1
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+type exprValue struct {
+	f   float64
+	d   time.Duration
+	dur bool
+}
+
+type exprParser struct {
+	s   string
+	pos int
+}
+
+// evalExpression evaluates +, -, *, / and parentheses over numbers and durations.
+func evalExpression(s string) (any, error) {
+	p := &exprParser{s: s}
+	v, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, ErrInvalid
+	}
+	if v.dur {
+		return v.d, nil
+	}
+	if v.f == float64(int64(v.f)) {
+		return int64(v.f), nil
+	}
+	return v.f, nil
+}
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+func (p *exprParser) sum() (exprValue, error) {
+	l, err := p.product()
+	if err != nil {
+		return l, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) || (p.s[p.pos] != '+' && p.s[p.pos] != '-') {
+			return l, nil
+		}
+		op := p.s[p.pos]
+		p.pos++
+		r, err := p.product()
+		if err != nil {
+			return l, err
+		}
+		if l.dur != r.dur {
+			return l, ErrInvalid
+		}
+		if op == '-' {
+			r.f, r.d = -r.f, -r.d
+		}
+		l.f, l.d = l.f+r.f, l.d+r.d
+	}
+}
+func (p *exprParser) product() (exprValue, error) {
+	l, err := p.unary()
+	if err != nil {
+		return l, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) || (p.s[p.pos] != '*' && p.s[p.pos] != '/') {
+			return l, nil
+		}
+		op := p.s[p.pos]
+		p.pos++
+		r, err := p.unary()
+		if err != nil {
+			return l, err
+		}
+		switch {
+		case op == '*' && l.dur && !r.dur:
+			l.d = time.Duration(float64(l.d) * r.f)
+		case op == '*' && !l.dur && r.dur:
+			l = exprValue{d: time.Duration(l.f * float64(r.d)), dur: true}
+		case op == '*' && !l.dur && !r.dur:
+			l.f *= r.f
+		case op == '/' && (r.f == 0 && !r.dur || r.dur && r.d == 0):
+			return l, ErrInvalid
+		case op == '/' && l.dur && r.dur:
+			l = exprValue{f: float64(l.d) / float64(r.d)}
+		case op == '/' && l.dur:
+			l.d = time.Duration(float64(l.d) / r.f)
+		case op == '/' && !r.dur:
+			l.f /= r.f
+		default:
+			return l, ErrInvalid
+		}
+	}
+}
+func (p *exprParser) unary() (exprValue, error) {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '-' {
+		p.pos++
+		v, err := p.unary()
+		v.f, v.d = -v.f, -v.d
+		return v, err
+	}
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		p.pos++
+		v, err := p.sum()
+		if err != nil {
+			return v, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return v, ErrInvalid
+		}
+		p.pos++
+		return v, nil
+	}
+	start := p.pos
+	for p.pos < len(p.s) && (!strings.ContainsRune(" \t+-*/()", rune(p.s[p.pos])) || isExponentSign(p.s[start:p.pos], p.s[p.pos])) {
+		p.pos++
+	}
+	tok := p.s[start:p.pos]
+	if tok == "" {
+		return exprValue{}, ErrInvalid
+	}
+	if f, err := strconv.ParseFloat(tok, 64); err == nil {
+		return exprValue{f: f}, nil
+	}
+	d, err := time.ParseDuration(tok)
+	if err != nil {
+		return exprValue{}, ErrInvalid
+	}
+	return exprValue{d: d, dur: true}, nil
+}
+
+// isExponentSign reports whether c is the sign of an exponent, as in 1e-3, following tok.
+func isExponentSign(tok string, c byte) bool {
+	if c != '+' && c != '-' || len(tok) < 2 || tok[len(tok)-1] != 'e' && tok[len(tok)-1] != 'E' {
+		return false
+	}
+	return strings.Trim(tok[:len(tok)-1], "0123456789.") == "" && strings.ContainsAny(tok, "0123456789")
+}
+
+// interpolateConfig expands references in the keys of m that T consumes, leaving unrelated
+// entries such as the rest of the process environment untouched.
+func interpolateConfig(m map[string]any, t reflect.Type, opt DTOOptions) (map[string]any, error) {
+	t = indirectType(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return m, nil
+	}
+	meta := dtoMetaFor(t, opt)
+	ip := newInterpolator(m, true)
+	for k, v := range m {
+		if !configKeyUsed(meta, k) {
+			continue
+		}
+		x, err := ip.expandValue(v, k)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = x
+	}
+	return m, nil
+}
+func configKeyUsed(meta *dtoStructMeta, key string) bool {
+	for _, f := range meta.fields {
+		for _, n := range f.names {
+			if strings.EqualFold(key, n) || len(key) > len(n) && key[len(n)] == '.' && strings.EqualFold(key[:len(n)], n) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandDefault evaluates references in a default tag against the source map, sibling defaults
+// and the process environment. Outside DTOProfileConfig default tags stay literal.
+func expandDefault(def string, m map[string]any, meta *dtoStructMeta, opt DTOOptions) (any, error) {
+	if !opt.configDefaults || !hasInterpolation(def) {
+		return def, nil
+	}
+	ip := newInterpolator(m, true)
+	ip.extra = func(name string) (string, any, bool) {
+		for _, f := range meta.fields {
+			if f.defaultValue == "" {
+				continue
+			}
+			for _, n := range f.names {
+				if strings.EqualFold(n, name) {
+					return "default:" + f.primary, f.defaultValue, true
+				}
+			}
+		}
+		return "", nil, false
+	}
+	return ip.expand(def, "")
+}
+
+// ErrUnknownKey reports a config key that no field of the target type consumes.
+var ErrUnknownKey = errors.New("convert: unknown key")
+
+// ConfigKeyScope controls how WithConfigStrict treats the keys of a source.
+type ConfigKeyScope uint8
+
+const (
+	// ConfigKeysStrict requires every key to match a field.
+	ConfigKeysStrict ConfigKeyScope = iota
+	// ConfigKeysIgnore skips the check, e.g. for the whole process environment.
+	ConfigKeysIgnore
+)
+
+// ConfigSourceInfo is implemented by sources that name themselves in errors and declare a key scope.
+// Sources without it are checked strictly.
+type ConfigSourceInfo interface {
+	SourceName() string
+	KeyScope() ConfigKeyScope
+}
+
+// ConfigOption configures LoadConfigWith.
+type ConfigOption func(*configPolicy)
+
+type configPolicy struct {
+	strict     bool
+	secrets    bool
+	profile    string
+	profileEnv string
+}
+
+// WithConfigStrict rejects keys that do not match a field, per source and according to its KeyScope.
+func WithConfigStrict() ConfigOption { return func(p *configPolicy) { p.strict = true } }
+
+// WithConfigSecrets resolves scheme://ref and ${scheme:ref} values through RegisterSecretResolver.
+// The resolved fields are listed in ConfigReport.SecretPaths.
+func WithConfigSecrets() ConfigOption { return func(p *configPolicy) { p.secrets = true } }
+
+// WithConfigProfile selects the profile overlay to apply, overriding the profile env var.
+func WithConfigProfile(name string) ConfigOption { return func(p *configPolicy) { p.profile = name } }
+
+// WithConfigProfileEnv changes the env var that selects the profile. The default is APP_PROFILE.
+func WithConfigProfileEnv(name string) ConfigOption {
+	return func(p *configPolicy) { p.profileEnv = name }
+}
+
+// ErrUnknownProfile reports a selected profile that no source defines. A profile taken from
+// the env var is only checked when some source defines profiles.
+var ErrUnknownProfile = errors.New("convert: unknown config profile")
+
+// ConfigProfileSource is implemented by sources with per-profile overlays stored outside the
+// base data, such as FileSource's sibling config.<profile>.json. ok is false when none exists.
+type ConfigProfileSource interface {
+	LoadProfile(profile string) (m map[string]any, ok bool, err error)
+}
+
+// LoadConfigWith is LoadConfig with loader options.
+func LoadConfigWith[T any](opts []ConfigOption, sources ...ConfigSource) (T, error) {
+	r, err := LoadConfigReport[T](opts, sources...)
+	return r.Value, err
+}
+
+// ConfigReport is a loaded config with the paths of the fields resolved from secret references.
+type ConfigReport[T any] struct {
+	Value       T
+	SecretPaths []string
+}
+
+// Redact returns an option that hides the resolved fields by path in Diff, MapTrace and DryRun
+// output. Resolved string values are redacted there anyway; this also covers fields that
+// were converted from a secret to another type.
+func (r ConfigReport[T]) Redact() DTOOption { return WithDTORedactPaths(r.SecretPaths...) }
+
+// LoadConfigReport is LoadConfigWith that also reports which fields were resolved from secrets.
+func LoadConfigReport[T any](opts []ConfigOption, sources ...ConfigSource) (ConfigReport[T], error) {
+	var z ConfigReport[T]
+	p := configPolicy{profileEnv: "APP_PROFILE"}
+	for _, o := range opts {
+		if o != nil {
+			o(&p)
+		}
+	}
+	profile := p.profile
+	if profile == "" && p.profileEnv != "" {
+		profile = os.Getenv(p.profileEnv)
+	}
+	if strings.ContainsAny(profile, `/\`) || strings.Contains(profile, "..") {
+		return z, errf("%w: config profile %q", ErrInvalid, profile)
+	}
+	t := reflect.TypeFor[T]()
+	opt := dtoOptionsFrom([]DTOOption{Config()})
+	merged := map[string]any{}
+	var errs []error
+	var available []string
+	found := false
+	for i, s := range sources {
+		m, err := s.Load()
+		if err != nil {
+			return z, err
+		}
+		m, names, ok, err := applyConfigProfile(s, m, profile)
+		if err != nil {
+			return z, err
+		}
+		found = found || ok
+		for _, n := range names {
+			available = appendUniqueString(available, n)
+		}
+		if p.strict {
+			errs = append(errs, checkConfigSource(s, i, m, t, opt)...)
+		}
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	// A profile picked up from the environment only has to exist once some source defines
+	// profiles; an explicit WithConfigProfile always does.
+	if profile != "" && !found && (p.profile != "" || len(available) > 0) {
+		if len(available) == 0 {
+			return z, errf("%w %q, no profiles defined", ErrUnknownProfile, profile)
+		}
+		sort.Strings(available)
+		return z, errf("%w %q, available: %s", ErrUnknownProfile, profile, strings.Join(available, ", "))
+	}
+	if len(errs) > 0 {
+		return z, MultiError{Errors: errs}
+	}
+	merged, err := interpolateConfig(merged, t, opt)
+	if err != nil {
+		return z, err
+	}
+	dto := []DTOOption{Config(), WithDTOFlatten()}
+	if p.secrets {
+		dto = append(dto, WithDTOSecrets(), func(o *DTOOptions) { o.secretPaths = &z.SecretPaths })
+	}
+	z.Value, err = DTOTo[T](merged, dto...)
+	return z, err
+}
+
+// applyConfigProfile strips the top-level profiles section from m and deep-merges the selected
+// overlay, then the source's external overlay, over the base. names lists the profiles section keys.
+func applyConfigProfile(s ConfigSource, m map[string]any, profile string) (out map[string]any, names []string, found bool, err error) {
+	out = make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	sections, _ := out["profiles"].(map[string]any)
+	delete(out, "profiles")
+	for n := range sections {
+		names = append(names, n)
+	}
+	if profile == "" {
+		return out, names, false, nil
+	}
+	for n, v := range sections {
+		if !strings.EqualFold(n, profile) {
+			continue
+		}
+		overlay, ok := v.(map[string]any)
+		if !ok {
+			return nil, nil, false, PathError("profiles."+n, KindOf(v), KindMap, v, ErrUnsupported)
+		}
+		out, found = mergeConfigMaps(out, overlay), true
+	}
+	if ps, ok := s.(ConfigProfileSource); ok {
+		overlay, ok, err := ps.LoadProfile(profile)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if ok {
+			delete(overlay, "profiles")
+			out, found = mergeConfigMaps(out, overlay), true
+		}
+	}
+	return out, names, found, nil
+}
+
+// mergeConfigMaps returns base with overlay merged in; nested maps merge key by key and any
+// other overlay value replaces the base value. Neither input is modified.
+func mergeConfigMaps(base, overlay map[string]any) map[string]any {
+	out := make(map[string]any, len(base)+len(overlay))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range overlay {
+		if om, ok := v.(map[string]any); ok {
+			if bm, ok := out[k].(map[string]any); ok {
+				out[k] = mergeConfigMaps(bm, om)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func checkConfigSource(s ConfigSource, i int, m map[string]any, t reflect.Type, opt DTOOptions) []error {
+	name := "source " + strconv.Itoa(i+1)
+	if info, ok := s.(ConfigSourceInfo); ok {
+		if info.KeyScope() == ConfigKeysIgnore {
+			return nil
+		}
+		name = info.SourceName()
+	}
+	display := func(k string) string { return k }
+	if d, ok := s.(interface{ displayKey(string) string }); ok {
+		display = d.displayKey
+	}
+	return checkConfigKeys(m, t, "", name, display, opt, 0)
+}
+
+// checkConfigKeys reports every key of m, recursively, that no field of t consumes.
+// Map and interface fields accept any subkey; dotted keys are followed into nested structs.
+func checkConfigKeys(m map[string]any, t reflect.Type, path, source string, display func(string) string, opt DTOOptions, depth int) []error {
+	t = indirectType(t)
+	if t == nil || !isConfigGroup(t) || depth > opt.MaxDepth {
+		return nil
+	}
+	meta := dtoMetaFor(t, opt)
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, k := range keys {
+		v := m[k]
+		if f, ok := configFieldNamed(meta, k); ok {
+			errs = append(errs, checkConfigValue(v, f.structField.Type, mapPath(path, k), source, display, opt, depth)...)
+			continue
+		}
+		if head, tail, ok := strings.Cut(k, "."); ok {
+			if f, ok := configFieldNamed(meta, head); ok && isConfigGroup(indirectType(f.structField.Type)) {
+				errs = append(errs, checkConfigKeys(map[string]any{tail: v}, f.structField.Type, mapPath(path, head), source, display, opt, depth+1)...)
+				continue
+			}
+		}
+		msg := errf("%w %q in %s", ErrUnknownKey, display(mapPath(path, k)), source)
+		if near := nearestConfigKey(meta, k); near != "" {
+			msg = errf("%w %q in %s, did you mean %q?", ErrUnknownKey, display(mapPath(path, k)), source, display(mapPath(path, near)))
+		}
+		errs = append(errs, PathError(mapPath(path, k), KindOf(v), KindInvalid, nil, msg))
+	}
+	return errs
+}
+func checkConfigValue(v any, t reflect.Type, path, source string, display func(string) string, opt DTOOptions, depth int) []error {
+	t = indirectType(t)
+	switch t.Kind() {
+	case reflect.Struct:
+		if sub, ok := v.(map[string]any); ok {
+			return checkConfigKeys(sub, t, path, source, display, opt, depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := v.([]any)
+		if !ok {
+			return nil
+		}
+		var errs []error
+		for i, item := range items {
+			errs = append(errs, checkConfigValue(item, t.Elem(), indexPath(path, i), source, display, opt, depth+1)...)
+		}
+		return errs
+	}
+	return nil
+}
+func configFieldNamed(meta *dtoStructMeta, key string) (dtoFieldMeta, bool) {
+	for _, f := range meta.fields {
+		for _, n := range f.names {
+			if strings.EqualFold(n, key) {
+				return f, true
+			}
+		}
+	}
+	return dtoFieldMeta{}, false
+}
+
+// isConfigGroup reports whether t is a struct whose fields are config keys of their own.
+func isConfigGroup(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) && !reflect.PointerTo(t).Implements(reflect.TypeOf((*FromAny)(nil)).Elem())
+}
+
+// nearestConfigKey returns the field name closest to key, or "" when nothing is close enough
+// to be a plausible typo.
+func nearestConfigKey(meta *dtoStructMeta, key string) string {
+	best, bestDist := "", max(1, len(key)/3)+1
+	for _, f := range meta.fields {
+		for _, n := range f.names {
+			if d := editDistance(strings.ToLower(key), strings.ToLower(n)); d < bestDist {
+				best, bestDist = n, d
+			}
+		}
+	}
+	return best
+}
+
+// editDistance is the optimal string alignment distance: insertions, deletions, substitutions
+// and adjacent transpositions each cost one.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+// ConfigField documents one key accepted by LoadConfig.
+type ConfigField struct {
+	Key, Env, Type, Default, Validate, Desc string
+	Required, Sensitive                     bool
+	Depth                                   int
+	Group                                   bool
+}
+
+// ConfigFields lists the keys LoadConfig accepts for T using the same metadata and tag
+// stack as the loader. Env is empty for nested keys, which cannot be read from env vars.
+func ConfigFields[T any]() []ConfigField {
+	var z T
+	return configFieldsFor(reflect.TypeOf(z), "", 0, dtoOptionsFrom([]DTOOption{Config()}))
+}
+func configFieldsFor(t reflect.Type, prefix string, depth int, opt DTOOptions) []ConfigField {
+	t = indirectType(t)
+	if t == nil || t.Kind() != reflect.Struct || depth > opt.MaxDepth {
+		return nil
+	}
+	var out []ConfigField
+	for _, f := range dtoMetaFor(t, opt).fields {
+		key := joinPath(prefix, configKeyName(f))
+		cf := ConfigField{Key: key, Type: schemaType(f.structField.Type), Default: f.defaultValue, Validate: f.structField.Tag.Get("validate"), Desc: f.structField.Tag.Get("desc"), Required: f.required, Sensitive: f.sensitive, Depth: depth}
+		if depth == 0 {
+			cf.Env = tagName(f.structField.Tag.Get("env"))
+			if cf.Env == "" {
+				cf.Env = strings.ToUpper(key)
+			}
+		}
+		if ft := indirectType(f.structField.Type); isConfigGroup(ft) {
+			cf.Group, cf.Env = true, ""
+			out = append(out, cf)
+			out = append(out, configFieldsFor(ft, key, depth+1, opt)...)
+			continue
+		}
+		out = append(out, cf)
+	}
+	return out
+}
+func configKeyName(f dtoFieldMeta) string {
+	for _, tag := range []string{"json", "convert"} {
+		if n := tagName(f.structField.Tag.Get(tag)); n != "" {
+			return n
+		}
+	}
+	return snakeName(f.structField.Name)
+}
+
+// ConfigMarkdown renders a Markdown reference table for the keys of T.
+func ConfigMarkdown[T any]() string {
+	var b strings.Builder
+	b.WriteString("| Key | Env | Type | Default | Required | Validation | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+	for _, f := range ConfigFields[T]() {
+		if f.Group {
+			continue
+		}
+		def := f.Default
+		if f.Sensitive && def != "" {
+			def = redactedValue
+		}
+		req := ""
+		if f.Required {
+			req = "yes"
+		}
+		cells := []string{"`" + f.Key + "`", mdCode(f.Env), f.Type, mdCode(def), req, mdCode(f.Validate), f.Desc}
+		b.WriteString("|")
+		for _, c := range cells {
+			b.WriteString(" ")
+			b.WriteString(strings.ReplaceAll(c, "|", "\\|"))
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+func mdCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
+
+// ConfigEnvExample renders a commented .env.example for the env-loadable keys of T.
+func ConfigEnvExample[T any]() string {
+	var b strings.Builder
+	for _, f := range ConfigFields[T]() {
+		if f.Env == "" {
+			continue
+		}
+		writeConfigComment(&b, f, "")
+		val := f.Default
+		if f.Sensitive {
+			val = ""
+		}
+		if !f.Required && f.Default == "" {
+			b.WriteString("# ")
+		}
+		b.WriteString(f.Env)
+		b.WriteString("=")
+		b.WriteString(val)
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+// ConfigYAMLExample renders a commented config.example.yaml for the keys of T.
+func ConfigYAMLExample[T any]() string {
+	var b strings.Builder
+	for _, f := range ConfigFields[T]() {
+		indent := strings.Repeat("  ", f.Depth)
+		name := f.Key[strings.LastIndexByte(f.Key, '.')+1:]
+		writeConfigComment(&b, f, indent)
+		b.WriteString(indent)
+		b.WriteString(name)
+		b.WriteString(":")
+		if !f.Group {
+			b.WriteString(" ")
+			b.WriteString(yamlExampleValue(f))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+func writeConfigComment(b *strings.Builder, f ConfigField, indent string) {
+	if f.Desc != "" {
+		b.WriteString(indent + "# " + f.Desc + "\n")
+	}
+	if f.Group {
+		return
+	}
+	meta := []string{f.Type}
+	if f.Required {
+		meta = append(meta, "required")
+	}
+	if f.Default != "" && !f.Sensitive {
+		meta = append(meta, "default "+f.Default)
+	}
+	if f.Validate != "" {
+		meta = append(meta, "validate "+f.Validate)
+	}
+	if f.Sensitive {
+		meta = append(meta, "sensitive")
+	}
+	b.WriteString(indent + "# (" + strings.Join(meta, ", ") + ")\n")
+}
+func yamlExampleValue(f ConfigField) string {
+	def := f.Default
+	if f.Sensitive {
+		def = ""
+	}
+	switch f.Type {
+	case "bool", "integer", "unsigned", "number":
+		if def == "" {
+			return "null"
+		}
+		return def
+	case "array":
+		if def == "" {
+			return "[]"
+		}
+		parts := strings.Split(def, ",")
+		for i, p := range parts {
+			parts[i] = strconv.Quote(strings.TrimSpace(p))
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case "object":
+		return "{}"
+	}
+	return strconv.Quote(def)
+}
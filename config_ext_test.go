@@ -0,0 +1,258 @@
+package convert
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type secretCfg struct {
+	Host     string `json:"host"`
+	Password string `json:"password"`
+	DSN      string `json:"dsn"`
+	APIKey   string `json:"api_key"`
+}
+
+func TestConfigSecretResolvers(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "db")
+	if err := os.WriteFile(file, []byte("file-pass\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CFG_TEST_DB_USER", "svc")
+	RegisterSecretResolver("vault", func(ref string) (string, error) {
+		if ref != "kv/app#api_key" {
+			return "", ErrSecretNotFound
+		}
+		return "vault-key", nil
+	})
+	defer UnregisterSecretResolver("vault")
+	src := map[string]any{"host": "db.local", "password": "file://" + file, "dsn": "pg://${env:CFG_TEST_DB_USER}@db.local", "api_key": "vault://kv/app#api_key"}
+	if plain, err := LoadConfig[secretCfg](MapSource(src)); err != nil || plain.Password != "file://"+file {
+		t.Fatalf("secrets resolved without opting in: %+v %v", plain, err)
+	}
+	secrets := []ConfigOption{WithConfigSecrets()}
+	rep, err := LoadConfigReport[secretCfg](secrets, MapSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := rep.Value
+	if cfg.Password != "file-pass" || cfg.DSN != "pg://svc@db.local" || cfg.APIKey != "vault-key" {
+		t.Fatalf("bad secrets: %+v", cfg)
+	}
+	if len(rep.SecretPaths) != 3 {
+		t.Fatalf("secret paths: %v", rep.SecretPaths)
+	}
+	for _, d := range Diff(secretCfg{}, cfg, rep.Redact()) {
+		if d.Path != "host" && d.New != redactedValue {
+			t.Fatalf("secret leaked in diff: %+v", d)
+		}
+	}
+	tr := MapTrace[secretCfg](map[string]any{"password": "file-pass", "host": "svc"}, rep.Redact())
+	if tr.Steps[1].Value != redactedValue || tr.Steps[0].Value != "svc" {
+		t.Fatalf("trace redaction: %+v", tr.Steps)
+	}
+	for _, d := range Diff(secretCfg{}, cfg) {
+		if (d.Path == "password" || d.Path == "dsn" || d.Path == "api_key") && d.New != redactedValue {
+			t.Fatalf("resolved secret not redacted by default: %+v", d)
+		}
+	}
+	if tr := MapTrace[secretCfg](map[string]any{"password": "file-pass", "host": "other"}); tr.Steps[1].Value != redactedValue || tr.Steps[0].Value != "other" {
+		t.Fatalf("default trace redaction: %+v", tr.Steps)
+	}
+	if rep := DryRun[secretCfg](map[string]any{"password": "vault-key", "host": "db.local"}); rep.Steps[1].Value != redactedValue {
+		t.Fatalf("dry run redaction: %+v", rep.Steps)
+	}
+	_, err = LoadConfigWith[secretCfg](secrets, MapSource(map[string]any{"password": "${env:CFG_TEST_MISSING}"}))
+	if !errors.Is(err, ErrSecretNotFound) || !strings.Contains(err.Error(), "password") {
+		t.Fatalf("expected path-aware missing secret error, got %v", err)
+	}
+}
+
+type interpCfg struct {
+	Host        string        `json:"host"`
+	BaseTimeout time.Duration `json:"base_timeout" default:"5s"`
+	Timeout     time.Duration `json:"timeout"`
+	Idle        time.Duration `json:"idle" default:"$(( ${base_timeout} * 3 ))"`
+	URL         string        `json:"url"`
+	Label       string        `json:"label"`
+	Workers     int           `json:"workers"`
+	Name        string        `json:"name" default:"${APP_NAME_UNSET_X:-svc}-${host}"`
+}
+
+func TestConfigInterpolation(t *testing.T) {
+	cfg, err := LoadConfig[interpCfg](MapSource(map[string]any{
+		"host":         "db.local",
+		"base_timeout": "2s",
+		"timeout":      "$(( ${base_timeout} * 2 + 500ms ))",
+		"url":          "http://${host}:${CFG_TEST_PORT_UNSET:-8080}/x",
+		"workers":      "$(( (${cpu} + 1) * 2 ))",
+		"label":        "${cpu} - ${host}",
+		"cpu":          4,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 4500*time.Millisecond || cfg.URL != "http://db.local:8080/x" || cfg.Workers != 10 {
+		t.Fatalf("bad interpolation: %+v", cfg)
+	}
+	if cfg.Idle != 6*time.Second || cfg.Name != "svc-db.local" || cfg.Label != "4 - db.local" {
+		t.Fatalf("bad default interpolation: %+v", cfg)
+	}
+	if m, err := Interpolate(map[string]any{"n": "$(( 1e-3 * 2 ))", "s": "$${host} and ${n}"}); err != nil || m["n"] != 0.002 || m["s"] != "${host} and 0.002" {
+		t.Fatalf("exponent and escape: %v %v", m, err)
+	}
+	if _, err := Interpolate(map[string]any{"n": "$(( 2 * x ))"}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected invalid expression, got %v", err)
+	}
+	type envDefault struct {
+		Home string `json:"home" default:"${CFG_TEST_HOME_X:-none}"`
+	}
+	t.Setenv("CFG_TEST_HOME_X", "/home/x")
+	if v, err := DTOTo[envDefault](map[string]any{}); err != nil || v.Home != "${CFG_TEST_HOME_X:-none}" {
+		t.Fatalf("plain DTO defaults stay literal: %+v %v", v, err)
+	}
+	if v, err := LoadConfig[envDefault](MapSource(map[string]any{})); err != nil || v.Home != "/home/x" {
+		t.Fatalf("config defaults read the environment: %+v %v", v, err)
+	}
+	_, err = LoadConfig[interpCfg](MapSource(map[string]any{"host": "${url}", "url": "${host}/x"}))
+	if !errors.Is(err, ErrReferenceCycle) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	_, err = Interpolate(map[string]any{"host": "${nope_missing_key}"})
+	if !errors.Is(err, ErrUnknownReference) {
+		t.Fatalf("expected unknown reference, got %v", err)
+	}
+}
+
+type docDB struct {
+	Host     string `json:"host" default:"localhost" desc:"Database host"`
+	Password string `json:"password" sensitive:"true" validate:"required"`
+}
+type docCfg struct {
+	Port    int           `json:"port" env:"APP_PORT" default:"8080" desc:"HTTP listen port"`
+	Timeout time.Duration `json:"timeout" default:"5s"`
+	Tags    []string      `json:"tags"`
+	DB      docDB         `json:"db"`
+}
+
+func TestConfigDocs(t *testing.T) {
+	md := ConfigMarkdown[docCfg]()
+	for _, want := range []string{"| `port` | `APP_PORT` | integer | `8080` |  |  | HTTP listen port |", "| `db.password` |  | string |  | yes | `required` |", "| `timeout` | `TIMEOUT` | duration | `5s` |"} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	env := ConfigEnvExample[docCfg]()
+	if !strings.Contains(env, "# HTTP listen port\n# (integer, default 8080)\nAPP_PORT=8080\n") || !strings.Contains(env, "# TAGS=") || strings.Contains(env, "DB_") {
+		t.Fatalf("bad env example:\n%s", env)
+	}
+	yaml := ConfigYAMLExample[docCfg]()
+	if !strings.Contains(yaml, "db:\n  # Database host\n  # (string, default localhost)\n  host: \"localhost\"\n") || !strings.Contains(yaml, "  password: \"\"") {
+		t.Fatalf("bad yaml example:\n%s", yaml)
+	}
+	t.Setenv("APP_PORT", "9090")
+	cfg, err := LoadConfig[docCfg](EnvSource(), MapSource(map[string]any{"db": map[string]any{"password": "x"}}))
+	if err != nil || cfg.Port != 9090 {
+		t.Fatalf("documented env var not honoured: %+v %v", cfg, err)
+	}
+}
+
+func TestConfigStrictKeys(t *testing.T) {
+	type strictCfg struct {
+		Timeout time.Duration `json:"timeout"`
+		Port    int           `json:"port"`
+		DB      struct {
+			Host string `json:"host"`
+		} `json:"db"`
+		Labels map[string]string `json:"labels"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"tiemout":"5s","db":{"hots":"x"},"db.host":"y","labels":{"any":"ok"},"zzz":1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("UNRELATED_VAR", "1")
+	t.Setenv("SVC_PROT", "80")
+	_, err := LoadConfigWith[strictCfg]([]ConfigOption{WithConfigStrict()}, FileSource(path), EnvSource(), EnvPrefixSource("SVC_"))
+	var me MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 4 || !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected four unknown keys, got %v", err)
+	}
+	msg := err.Error()
+	for _, want := range []string{`"tiemout" in ` + path + `, did you mean "timeout"?`, `"db.hots"`, `did you mean "db.host"?`, `"SVC_PROT" in env SVC_*, did you mean "SVC_PORT"?`, `unknown key "zzz" in ` + path} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("missing %q in %s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "UNRELATED_VAR") {
+		t.Fatalf("process env must not be checked: %s", msg)
+	}
+	for _, e := range me.Errors {
+		if d := new(ErrorDetail); errors.As(e, &d) && d.Value != nil {
+			t.Fatalf("unknown keys must not carry their value: %#v", d)
+		}
+	}
+	t.Setenv("SVC_PROT", "")
+	os.Unsetenv("SVC_PROT")
+	t.Setenv("SVC_PORT", "81")
+	cfg, err := LoadConfigWith[strictCfg]([]ConfigOption{WithConfigStrict()}, MapSource(map[string]any{"timeout": "1s"}), EnvPrefixSource("SVC_"))
+	if err != nil || cfg.Port != 81 || cfg.Timeout != time.Second {
+		t.Fatalf("prefix source: %+v %v", cfg, err)
+	}
+	if _, err := LoadConfig[strictCfg](MapSource(map[string]any{"tiemout": "1s"})); err != nil {
+		t.Fatalf("unknown keys are only rejected in strict mode: %v", err)
+	}
+}
+
+func TestConfigProfiles(t *testing.T) {
+	type profCfg struct {
+		Port int `json:"port"`
+		DB   struct {
+			Host string `json:"host"`
+			Pool int    `json:"pool"`
+		} `json:"db"`
+		Debug bool `json:"debug"`
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	base := `{"port":80,"db":{"host":"localhost","pool":5},"profiles":{"prod":{"db":{"host":"db.prod"}},"dev":{"debug":true}}}`
+	if err := os.WriteFile(path, []byte(base), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.prod.json"), []byte(`{"port":443}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_PROFILE", "prod")
+	cfg, err := LoadConfigWith[profCfg]([]ConfigOption{WithConfigStrict()}, FileSource(path))
+	if err != nil || cfg.Port != 443 || cfg.DB.Host != "db.prod" || cfg.DB.Pool != 5 || cfg.Debug {
+		t.Fatalf("prod overlay: %+v %v", cfg, err)
+	}
+	cfg, err = LoadConfigWith[profCfg]([]ConfigOption{WithConfigProfile("dev")}, FileSource(path))
+	if err != nil || !cfg.Debug || cfg.Port != 80 || cfg.DB.Host != "localhost" {
+		t.Fatalf("dev overlay: %+v %v", cfg, err)
+	}
+	cfg, err = LoadConfigWith[profCfg]([]ConfigOption{WithConfigProfileEnv("NO_SUCH_PROFILE_VAR")}, FileSource(path))
+	if err != nil || cfg.Port != 80 || cfg.DB.Host != "localhost" {
+		t.Fatalf("no profile: %+v %v", cfg, err)
+	}
+	_, err = LoadConfigWith[profCfg]([]ConfigOption{WithConfigProfile("stage")}, FileSource(path))
+	if !errors.Is(err, ErrUnknownProfile) || !strings.Contains(err.Error(), "dev, prod") {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+	if _, err := LoadConfigWith[profCfg]([]ConfigOption{WithConfigProfile("qa")}, MapSource(map[string]any{"port": 1})); !errors.Is(err, ErrUnknownProfile) {
+		t.Fatalf("profile without sections must fail, got %v", err)
+	}
+	if cfg, err := LoadConfig[profCfg](MapSource(map[string]any{"port": 1})); err != nil || cfg.Port != 1 {
+		t.Fatalf("env profile without sections is ignored: %+v %v", cfg, err)
+	}
+	if _, err := LoadConfigWith[profCfg]([]ConfigOption{WithConfigProfile("../prod")}, FileSource(path)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected invalid profile name, got %v", err)
+	}
+	m := map[string]any{"port": 1, "profiles": map[string]any{"prod": map[string]any{"port": 2}}}
+	if cfg, err := LoadConfig[profCfg](MapSource(m)); err != nil || cfg.Port != 2 || m["port"] != 1 || m["profiles"] == nil {
+		t.Fatalf("map overlay: %+v %v %v", cfg, err, m)
+	}
+}
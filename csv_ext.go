@@ -0,0 +1,420 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVSliceFormat selects how slice fields are written to and read from a single cell.
+type CSVSliceFormat int
+
+const (
+	CSVSliceDelimited CSVSliceFormat = iota // a,b,c split on CSVOptions.SliceSep
+	CSVSliceJSON                            // ["a","b","c"]
+)
+
+// CSVOptions describes a CSV dialect for CSVSeqWith, ReadCSVWith and WriteCSVWith.
+type CSVOptions struct {
+	Comma          rune
+	LazyQuotes     bool
+	VariableFields bool              // accept records with a field count other than the first record's
+	BOM            bool              // write a UTF-8 byte order mark; one is always stripped on read
+	NoHeader       bool              // no header row: map columns with csv:"#1", csv:"#2", ...
+	Aliases        map[string]string // header alias (case-insensitive) -> field name
+	SliceFormat    CSVSliceFormat
+	SliceSep       string
+	DTOOptions     []DTOOption
+	Batch          []DTOBatchOption
+}
+
+type CSVOption func(*CSVOptions)
+
+// DefaultCSVOptions returns the encoding/csv dialect: comma separated with a header row,
+// and comma separated slice cells.
+func DefaultCSVOptions() CSVOptions { return CSVOptions{Comma: ',', SliceSep: ","} }
+
+// WithCSVComma sets the field delimiter, e.g. '\t' for TSV or ';' for European Excel.
+func WithCSVComma(r rune) CSVOption { return func(o *CSVOptions) { o.Comma = r } }
+func WithCSVLazyQuotes() CSVOption  { return func(o *CSVOptions) { o.LazyQuotes = true } }
+func WithCSVVariableFields() CSVOption {
+	return func(o *CSVOptions) { o.VariableFields = true }
+}
+func WithCSVBOM() CSVOption       { return func(o *CSVOptions) { o.BOM = true } }
+func WithCSVNoHeader() CSVOption  { return func(o *CSVOptions) { o.NoHeader = true } }
+func WithCSVSliceJSON() CSVOption { return func(o *CSVOptions) { o.SliceFormat = CSVSliceJSON } }
+func WithCSVSliceSeparator(sep string) CSVOption {
+	return func(o *CSVOptions) {
+		if sep != "" {
+			o.SliceFormat, o.SliceSep = CSVSliceDelimited, sep
+		}
+	}
+}
+
+// WithCSVHeaderAlias maps alternative header spellings onto a field name.
+func WithCSVHeaderAlias(name string, aliases ...string) CSVOption {
+	return func(o *CSVOptions) {
+		if o.Aliases == nil {
+			o.Aliases = map[string]string{}
+		}
+		for _, a := range aliases {
+			o.Aliases[strings.ToLower(a)] = name
+		}
+	}
+}
+func WithCSVDTOOptions(opts ...DTOOption) CSVOption {
+	return func(o *CSVOptions) { o.DTOOptions = append(o.DTOOptions, opts...) }
+}
+
+// WithCSVBatch sets the record error policy for CSVSeqWith and ReadCSVWith.
+func WithCSVBatch(opts ...DTOBatchOption) CSVOption {
+	return func(o *CSVOptions) { o.Batch = append(o.Batch, opts...) }
+}
+
+func csvOptionsFrom(opts []CSVOption) CSVOptions {
+	o := DefaultCSVOptions()
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// csvDecoder turns records into DTO input maps for one dialect and target type.
+type csvDecoder struct {
+	headers    []string
+	positional bool
+	dotted     bool
+	jsonCells  map[string]Kind // lower-cased dotted paths of map cells, and of slice cells in JSON mode
+}
+
+func newCSVDecoder(t reflect.Type, headers []string, o CSVOptions, opt DTOOptions) *csvDecoder {
+	d := &csvDecoder{headers: headers, positional: o.NoHeader}
+	for i, h := range headers {
+		h = strings.TrimSpace(h)
+		if name, ok := o.Aliases[strings.ToLower(h)]; ok {
+			h = name
+		}
+		d.headers[i] = h
+		d.dotted = d.dotted || strings.Contains(h, ".")
+	}
+	if t = indirectType(t); t.Kind() == reflect.Struct {
+		d.scan(t, "", o, opt, 0)
+	}
+	return d
+}
+
+// scan records positional fields and the JSON-encoded cells of t, recursing into nested
+// structs so that address.tags is found as well as tags.
+func (d *csvDecoder) scan(t reflect.Type, prefix string, o CSVOptions, opt DTOOptions, depth int) {
+	for _, f := range dtoMetaFor(t, opt).fields {
+		ft := indirectType(f.structField.Type)
+		kind := KindInvalid
+		switch {
+		case ft.Kind() == reflect.Map:
+			kind = KindMap
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8 && o.SliceFormat == CSVSliceJSON:
+			kind = KindSlice
+		}
+		nested := ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) && !selfEncoding(ft) && depth < opt.MaxDepth
+		for _, n := range f.names {
+			d.positional = d.positional || prefix == "" && strings.HasPrefix(n, "#")
+			switch {
+			case kind != KindInvalid:
+				if d.jsonCells == nil {
+					d.jsonCells = map[string]Kind{}
+				}
+				d.jsonCells[strings.ToLower(prefix+n)] = kind
+			case nested:
+				d.scan(ft, prefix+n+".", o, opt, depth+1)
+			}
+		}
+	}
+}
+
+func (d *csvDecoder) record(row []string) (map[string]any, error) {
+	m := make(map[string]any, len(row))
+	for i, cell := range row {
+		if d.positional {
+			m["#"+strconv.Itoa(i+1)] = cell
+		}
+		if i >= len(d.headers) || d.headers[i] == "" {
+			continue
+		}
+		h := d.headers[i]
+		m[h] = cell
+		if kind, ok := d.jsonCells[strings.ToLower(h)]; ok && cell != "" {
+			var v any
+			if err := json.Unmarshal([]byte(cell), &v); err != nil {
+				return nil, PathError(h, KindString, kind, cell, err)
+			}
+			m[h] = v
+		}
+	}
+	if d.dotted {
+		m = UnflattenMap(m)
+	}
+	return m, nil
+}
+
+// csvSource reads records in one dialect and turns them into DTO input maps.
+type csvSource struct {
+	cr     *csv.Reader
+	header []string // the header row as read, nil without one
+	dec    *csvDecoder
+	all    []DTOOption
+	tap    *csvTap // set when raw record text is kept
+}
+
+// csvTap keeps the bytes the csv.Reader has read but not yet handed out as a record, so
+// the raw text of a record can be recovered from its InputOffset.
+type csvTap struct {
+	r    io.Reader
+	buf  []byte
+	base int64 // input offset of buf[0]
+}
+
+func (t *csvTap) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	t.buf = append(t.buf, p[:n]...)
+	return n, err
+}
+
+// take returns the text up to input offset end and drops it from the buffer.
+func (t *csvTap) take(end int64) string {
+	k := int(end - t.base)
+	s := string(t.buf[:k])
+	t.buf, t.base = append(t.buf[:0], t.buf[k:]...), end
+	return s
+}
+
+// openCSV strips a BOM and reads the header row. It returns nil for empty input. With
+// keepRaw, raw returns the text of each record.
+func openCSV[T any](r io.Reader, o CSVOptions, keepRaw bool) (*csvSource, error) {
+	br := bufio.NewReader(r)
+	if b, _ := br.Peek(len(utf8BOM)); bytes.Equal(b, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+	s := &csvSource{}
+	in := io.Reader(br)
+	if keepRaw {
+		s.tap = &csvTap{r: br}
+		in = s.tap
+	}
+	s.cr = csv.NewReader(in)
+	s.cr.Comma, s.cr.LazyQuotes, s.cr.ReuseRecord = o.Comma, o.LazyQuotes, true
+	if o.VariableFields {
+		s.cr.FieldsPerRecord = -1
+	}
+	s.all = append([]DTOOption{WithDTOTags("csv", "json", "convert"), WithDTOSplit(WithSeparator(o.SliceSep), WithTrimSpace(), WithIgnoreEmpty())}, o.DTOOptions...)
+	if !o.NoHeader {
+		h, err := s.cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.header = append([]string(nil), h...)
+		s.raw()
+	}
+	s.dec = newCSVDecoder(reflect.TypeFor[T](), append([]string(nil), s.header...), o, dtoOptionsFrom(s.all))
+	return s, nil
+}
+
+// next reads one record and the 1-based line it starts on. The record is reused by the
+// following call.
+func (s *csvSource) next() ([]string, int, error) {
+	row, err := s.cr.Read()
+	if err != nil {
+		return row, 0, err // csv returns the record along with ErrFieldCount
+	}
+	line, _ := s.cr.FieldPos(0)
+	return row, line, nil
+}
+
+// raw returns the text of the record last read, without its line ending.
+func (s *csvSource) raw() string {
+	if s.tap == nil {
+		return ""
+	}
+	return strings.TrimRight(s.tap.take(s.cr.InputOffset()), "\r\n")
+}
+
+func csvConvert[T any](s *csvSource, row []string) (T, error) {
+	m, err := s.dec.record(row)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return DTOTo[T](m, s.all...)
+}
+
+// CSVSeqWith is CSVSeq for the dialect described by opts. Headers are trimmed and matched
+// through aliases; dotted headers such as address.city fill nested structs; without a
+// header row, or for fields tagged csv:"#n", the nth column is used.
+func CSVSeqWith[T any](r io.Reader, opts ...CSVOption) iter.Seq2[T, error] {
+	o := csvOptionsFrom(opts)
+	bo := dtoBatchOptionsFrom(o.Batch)
+	o.DTOOptions = append(o.DTOOptions[:len(o.DTOOptions):len(o.DTOOptions)], bo.DTOOptions...)
+	return func(yield func(T, error) bool) {
+		var zero T
+		s, err := openCSV[T](r, o, false)
+		if s == nil {
+			if err != nil {
+				yield(zero, err)
+			}
+			return
+		}
+		for i := 0; ; i++ {
+			row, _, err := s.next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			v, err := csvConvert[T](s, row)
+			if err != nil {
+				err = PathError(indexPath("", i), KindSlice, KindStruct, append([]string(nil), row...), err)
+			}
+			if !yieldRecord(yield, bo, v, err) {
+				return
+			}
+		}
+	}
+}
+
+// ReadCSVWith reads all records in the dialect described by opts.
+func ReadCSVWith[T any](r io.Reader, opts ...CSVOption) ([]T, error) {
+	var out []T
+	for v, err := range CSVSeqWith[T](r, opts...) {
+		if err != nil {
+			return out, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+type csvColumn struct {
+	header string
+	index  []int
+}
+
+// csvColumns lists the columns for t: nested structs become dotted headers, writeonly
+// fields are left out, and csv:"#n" columns are placed first in position order.
+func csvColumns(t reflect.Type, prefix string, index []int, opt DTOOptions, depth int) []csvColumn {
+	var out []csvColumn
+	for _, f := range dtoMetaFor(t, opt).fields {
+		if f.writeonly {
+			continue
+		}
+		idx := append(append([]int(nil), index...), f.index...)
+		ft := indirectType(f.structField.Type)
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) && !selfEncoding(ft) && depth < opt.MaxDepth {
+			out = append(out, csvColumns(ft, prefix+f.primary+".", idx, opt, depth+1)...)
+			continue
+		}
+		out = append(out, csvColumn{header: prefix + f.primary, index: idx})
+	}
+	if depth == 0 {
+		sort.SliceStable(out, func(i, j int) bool { return csvPosition(out[i].header) < csvPosition(out[j].header) })
+	}
+	return out
+}
+func csvPosition(h string) int {
+	if n, err := strconv.Atoi(strings.TrimPrefix(h, "#")); err == nil && strings.HasPrefix(h, "#") {
+		return n
+	}
+	return int(^uint(0) >> 1)
+}
+
+func csvCell(v reflect.Value, path string, o CSVOptions) (string, error) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "", nil
+	}
+	isSlice := (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8
+	if v.Kind() == reflect.Map || isSlice && o.SliceFormat == CSVSliceJSON {
+		if v.Kind() != reflect.Array && v.IsNil() {
+			return "", nil
+		}
+		b, err := json.Marshal(v.Interface())
+		return string(b), err
+	}
+	parts, err := fieldStrings(v, path)
+	return strings.Join(parts, o.SliceSep), err
+}
+
+// WriteCSVWith writes rows in the dialect described by opts. Nested structs are written as
+// dotted columns, maps as JSON cells, and slices as delimited or JSON cells. T must be a
+// struct type.
+func WriteCSVWith[T any](w io.Writer, rows []T, opts ...CSVOption) error {
+	o := csvOptionsFrom(opts)
+	if len(rows) == 0 {
+		return nil
+	}
+	if o.BOM {
+		if _, err := w.Write(utf8BOM); err != nil {
+			return err
+		}
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = o.Comma
+	opt := dtoOptionsFrom(append([]DTOOption{CSV()}, o.DTOOptions...))
+	t := indirectType(reflect.TypeFor[T]())
+	if t.Kind() != reflect.Struct {
+		return PathError("", KindInvalid, KindStruct, nil, ErrUnsupported)
+	}
+	cols := csvColumns(t, "", nil, opt, 0)
+	rec := make([]string, len(cols))
+	if !o.NoHeader {
+		for i, c := range cols {
+			rec[i] = c.header
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	for n, row := range rows {
+		rv := reflect.ValueOf(row)
+		for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		for i, c := range cols {
+			var fv reflect.Value
+			if rv.Kind() == reflect.Struct {
+				fv = fieldByIndex(rv, c.index)
+			}
+			s, err := csvCell(fv, joinPath(indexPath("", n), c.header), o)
+			if err != nil {
+				return err
+			}
+			rec[i] = s
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,100 @@
+package convert
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type csvAddress struct {
+	City string `csv:"city"`
+	Zip  string `csv:"zip"`
+}
+
+type csvContact struct {
+	Name    string     `csv:"name"`
+	Tags    []string   `csv:"tags"`
+	Address csvAddress `csv:"address"`
+	Secret  string     `csv:"secret,writeonly"`
+}
+
+type csvPositional struct {
+	Code  string `csv:"#2"`
+	ID    int    `csv:"#1"`
+	Notes string `csv:"notes"`
+}
+
+func TestCSVDialects(t *testing.T) {
+	in := "\ufeff Full Name ;tags;address.city;address.zip\nAda;go| sql;London;N1\n"
+	got, err := ReadCSVWith[csvContact](strings.NewReader(in), WithCSVComma(';'), WithCSVSliceSeparator("|"), WithCSVHeaderAlias("name", "full name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []csvContact{{Name: "Ada", Tags: []string{"go", "sql"}, Address: csvAddress{City: "London", Zip: "N1"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("read: %+v", got)
+	}
+
+	var buf bytes.Buffer
+	want[0].Secret = "x"
+	if err := WriteCSVWith(&buf, want, WithCSVComma('\t'), WithCSVBOM(), WithCSVSliceJSON()); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); s != "\ufeffname\ttags\taddress.city\taddress.zip\nAda\t\"[\"\"go\"\",\"\"sql\"\"]\"\tLondon\tN1\n" {
+		t.Fatalf("write: %q", s)
+	}
+	back, err := ReadCSVWith[csvContact](&buf, WithCSVComma('\t'), WithCSVSliceJSON())
+	want[0].Secret = ""
+	if err != nil || !reflect.DeepEqual(back, want) {
+		t.Fatalf("round trip: %+v %v", back, err)
+	}
+
+	if _, err := ReadCSVWith[csvPositional](strings.NewReader("7,A\n8,B,extra\n"), WithCSVNoHeader()); err == nil {
+		t.Fatal("expected a field count error without WithCSVVariableFields")
+	}
+	pos, err := ReadCSVWith[csvPositional](strings.NewReader("7,A\n8,B,extra\n"), WithCSVNoHeader(), WithCSVVariableFields())
+	if err != nil || len(pos) != 2 || pos[0].ID != 7 || pos[1].Code != "B" {
+		t.Fatalf("positional: %+v %v", pos, err)
+	}
+	buf.Reset()
+	if err := WriteCSVWith(&buf, pos, WithCSVNoHeader()); err != nil || buf.String() != "7,A,\n8,B,\n" {
+		t.Fatalf("positional write: %q %v", buf.String(), err)
+	}
+
+	if _, err := ReadCSVWith[csvContact](strings.NewReader("tags\n[oops\n"), WithCSVSliceJSON()); err == nil {
+		t.Fatal("expected JSON cell error")
+	}
+}
+
+func TestCSVMapCellsAndBatchOptions(t *testing.T) {
+	type meta struct {
+		Labels map[string]string `csv:"labels"`
+		Tags   []string          `csv:"tags"`
+	}
+	type row struct {
+		Name   string            `csv:"name"`
+		Labels map[string]string `csv:"labels"`
+		Meta   meta              `csv:"meta"`
+	}
+	rows := []row{{Name: "a", Labels: map[string]string{"env": "prod"}, Meta: meta{Labels: map[string]string{"k": "v"}, Tags: []string{"x", "y"}}}}
+	for _, opts := range [][]CSVOption{nil, {WithCSVSliceJSON()}} {
+		var buf bytes.Buffer
+		if err := WriteCSVWith(&buf, rows, opts...); err != nil {
+			t.Fatal(err)
+		}
+		back, err := ReadCSVWith[row](&buf, opts...)
+		if err != nil || !reflect.DeepEqual(back, rows) {
+			t.Fatalf("map round trip %d: %+v %v", len(opts), back, err)
+		}
+	}
+	in := "name,zzz\na,1\n"
+	if _, err := ReadCSV[row](strings.NewReader(in)); err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range CSVSeq[row](strings.NewReader(in), DTOBatchWithOptions(WithDTOErrorUnused())) {
+		if err == nil {
+			t.Fatal("batch DTO options ignored")
+		}
+	}
+}
@@ -41,6 +41,11 @@ type DTOOptions struct {
 	MaxSliceLen     int
 	MaxMapSize      int
 	LosslessNumbers bool
+	ResolveSecrets  bool
+	// RedactPaths lists field paths whose values Diff, MapTrace and DryRun hide.
+	RedactPaths    []string
+	secretPaths    *[]string // collects paths resolved through ResolveSecrets
+	configDefaults bool      // expands references and expressions in default tags
 }
 
 // DefaultDTOOptions returns the default DTO conversion settings.
@@ -99,6 +104,15 @@ func WithDTOMaxMapSize(n int) DTOOption {
 	}
 }
 func WithDTOLosslessNumbers() DTOOption { return func(o *DTOOptions) { o.LosslessNumbers = true } }
+
+// WithDTOSecrets resolves scheme://ref and ${scheme:ref} string values through RegisterSecretResolver.
+func WithDTOSecrets() DTOOption { return func(o *DTOOptions) { o.ResolveSecrets = true } }
+
+// WithDTORedactPaths hides the values at paths in Diff, MapTrace and DryRun output, e.g. the
+// SecretPaths of a ConfigReport.
+func WithDTORedactPaths(paths ...string) DTOOption {
+	return func(o *DTOOptions) { o.RedactPaths = append(o.RedactPaths, paths...) }
+}
 func WithDTOStrict() DTOOption {
 	return func(o *DTOOptions) { o.ErrorUnused = true; o.LosslessNumbers = true; o.WeaklyTyped = false }
 }
@@ -175,6 +189,22 @@ func dtoSet(dst reflect.Value, src any, path string, opt DTOOptions) error {
 	if !dst.IsValid() || !dst.CanSet() {
 		return PathError(path, KindOf(src), KindInvalid, src, ErrUnsupported)
 	}
+	if opt.ResolveSecrets {
+		if s, ok := src.(string); ok {
+			resolved, changed, err := resolveSecretString(s)
+			if err != nil {
+				return PathError(path, KindString, KindOfReflect(dst), s, err)
+			}
+			if changed {
+				rememberSecret(resolved)
+				if opt.secretPaths != nil {
+					*opt.secretPaths = append(*opt.secretPaths, path)
+				}
+				opt.ResolveSecrets = false
+				return redactErrorValue(dtoSet(dst, resolved, path, opt))
+			}
+		}
+	}
 	if opt.DecodeHook != nil {
 		handled, err := opt.DecodeHook(DTOContext{Path: path, From: KindOf(src), To: KindOfReflect(dst)}, dst, src)
 		if err != nil {
@@ -465,7 +495,11 @@ func dtoStructFromStringMap(dst reflect.Value, m map[string]any, path string, op
 		fieldPath := joinPath(path, fm.primary)
 		if !found {
 			if fm.defaultValue != "" {
-				val, found = fm.defaultValue, true
+				def, err := expandDefault(fm.defaultValue, m, meta, opt)
+				if err != nil {
+					return PathError(fieldPath, KindString, KindOfReflect(field), fm.defaultValue, err)
+				}
+				val, found = def, true
 			}
 		}
 		if !found && opt.Flatten {
@@ -488,12 +522,16 @@ func dtoStructFromStringMap(dst reflect.Value, m map[string]any, path string, op
 		}
 		used[usedName] = struct{}{}
 		val = applyDTOTransforms(val, fm.transforms)
+		sensitive := fm.sensitive
+		if s, ok := val.(string); ok && opt.ResolveSecrets && hasSecretRef(s) {
+			sensitive = true
+		}
 		if err := dtoSet(field, val, fieldPath, opt); err != nil {
-			return err
+			return markSensitive(err, sensitive)
 		}
 		if fm.validate {
 			if err := validateReflectField(field, fm.structField, fieldPath); err != nil {
-				return err
+				return markSensitive(err, sensitive)
 			}
 		}
 	}
@@ -538,6 +576,7 @@ type dtoFieldMeta struct {
 	readonly     bool
 	writeonly    bool
 	sensitive    bool
+	from         []string
 }
 
 type dtoCacheKey struct {
@@ -590,7 +629,7 @@ func buildDTOMeta(t reflect.Type, prefix []int, opt DTOOptions, out *[]dtoFieldM
 		}
 		primary := firstName(names, snakeName(f.Name))
 		transforms, readonly, writeonly, sensitive := dtoTagOptions(f)
-		*out = append(*out, dtoFieldMeta{index: idx, names: names, primary: primary, defaultValue: f.Tag.Get("default"), required: isRequired(f), validate: f.Tag.Get("validate") != "", structField: f, transforms: transforms, readonly: readonly, writeonly: writeonly, sensitive: sensitive})
+		*out = append(*out, dtoFieldMeta{index: idx, names: names, primary: primary, defaultValue: f.Tag.Get("default"), required: isRequired(f), validate: f.Tag.Get("validate") != "", structField: f, transforms: transforms, readonly: readonly, writeonly: writeonly, sensitive: sensitive, from: dtoTagFrom(f)})
 	}
 }
 
@@ -3,6 +3,7 @@ package convert
 import (
 	"bufio"
 	"context"
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -41,11 +42,15 @@ var (
 	DTOProfileStrict = DTOProfile{Options: []DTOOption{WithDTOStrict()}}
 	DTOProfileAPI    = DTOProfile{Options: []DTOOption{WithDTOStrict(), WithDTOFlatten(), WithDTOMaxDepth(48), WithDTOMaxSliceLen(100000)}}
 	DTOProfileDB     = DTOProfile{Options: []DTOOption{WithDTOTags("db", "json", "convert"), WithDTOFlatten()}}
-	DTOProfileConfig = DTOProfile{Options: []DTOOption{WithDTOTags("env", "json", "convert"), WithDTOFlatten()}}
+	DTOProfileConfig = DTOProfile{Options: []DTOOption{WithDTOTags("env", "json", "convert"), WithDTOFlatten(), withDTOConfigDefaults}}
 	DTOProfileForm   = DTOProfile{Options: []DTOOption{WithDTOTags("form", "query", "json", "convert"), WithDTOFlatten()}}
 	DTOProfileCSV    = DTOProfile{Options: []DTOOption{WithDTOTags("csv", "json", "convert")}}
 )
 
+// withDTOConfigDefaults expands ${key}, ${VAR:-fallback} and $(( ... )) in default tags,
+// as LoadConfig does for config values.
+func withDTOConfigDefaults(o *DTOOptions) { o.configDefaults = true }
+
 func WithDTOProfile(p DTOProfile) DTOOption {
 	return func(o *DTOOptions) {
 		for _, opt := range p.Options {
@@ -152,12 +157,7 @@ func DTOBatch[T any](src any, opts ...DTOBatchOption) ([]T, error) {
 }
 
 func DTOBatchConvert[T any](src any, opts ...DTOBatchOption) DTOBatchReport[T] {
-	bo := DTOBatchOptions{Workers: 4}
-	for _, opt := range opts {
-		if opt != nil {
-			opt(&bo)
-		}
-	}
+	bo := dtoBatchOptionsFrom(opts)
 	items, err := collectSliceItems(src, WithTrimSpace())
 	if err != nil {
 		return DTOBatchReport[T]{Errors: []error{err}}
@@ -406,13 +406,15 @@ type DiffChange struct {
 	Kind string
 }
 
+// Diff lists the changed leaf paths between a and b. Values of sensitive fields, values
+// resolved from secrets and WithDTORedactPaths paths are redacted.
 func Diff(a, b any, opts ...DTOOption) []DiffChange {
-	return diffValue(reflect.ValueOf(a), reflect.ValueOf(b), "")
+	return diffValue(reflect.ValueOf(a), reflect.ValueOf(b), "", dtoOptionsFrom(opts))
 }
-func diffValue(a, b reflect.Value, path string) []DiffChange {
+func diffValue(a, b reflect.Value, path string, opt DTOOptions) []DiffChange {
 	if !a.IsValid() || !b.IsValid() {
 		if a.IsValid() != b.IsValid() {
-			return []DiffChange{{Path: path, Old: valueInterface(a), New: valueInterface(b), Kind: "change"}}
+			return []DiffChange{{Path: path, Old: redactValue(valueInterface(a), opt.redactPath(path)), New: redactValue(valueInterface(b), opt.redactPath(path)), Kind: "change"}}
 		}
 		return nil
 	}
@@ -432,12 +434,18 @@ func diffValue(a, b reflect.Value, path string) []DiffChange {
 		var out []DiffChange
 		meta := dtoMetaFor(a.Type(), DefaultDTOOptions())
 		for _, f := range meta.fields {
-			out = append(out, diffValue(fieldByIndex(a, f.index), fieldByIndex(b, f.index), joinPath(path, f.primary))...)
+			changes := diffValue(fieldByIndex(a, f.index), fieldByIndex(b, f.index), joinPath(path, f.primary), opt)
+			if f.sensitive {
+				for i := range changes {
+					changes[i].Old, changes[i].New = redactValue(changes[i].Old, true), redactValue(changes[i].New, true)
+				}
+			}
+			out = append(out, changes...)
 		}
 		return out
 	}
 	if !reflect.DeepEqual(valueInterface(a), valueInterface(b)) {
-		return []DiffChange{{Path: path, Old: valueInterface(a), New: valueInterface(b), Kind: "change"}}
+		return []DiffChange{{Path: path, Old: redactValue(valueInterface(a), opt.redactPath(path)), New: redactValue(valueInterface(b), opt.redactPath(path)), Kind: "change"}}
 	}
 	return nil
 }
@@ -537,14 +545,21 @@ func schemaType(t reflect.Type) string {
 	}
 }
 
+// FromQuery decodes v into T. Fields choose their serialization with the style= and
+// explode=false tag options; see QueryStyleDeepObject and friends.
 func FromQuery[T any](v url.Values, opts ...DTOOption) (T, error) {
-	return DTOTo[T](valuesToMap(v), append([]DTOOption{WithDTOTags("query", "form", "json", "convert")}, opts...)...)
+	all := append([]DTOOption{WithDTOTags("query", "form", "json", "convert")}, opts...)
+	return DTOTo[T](queryValuesMap(v, reflect.TypeFor[T](), dtoOptionsFrom(all)), all...)
 }
 func FromForm[T any](v url.Values, opts ...DTOOption) (T, error) {
-	return DTOTo[T](valuesToMap(v), append([]DTOOption{WithDTOTags("form", "query", "json", "convert")}, opts...)...)
+	all := append([]DTOOption{WithDTOTags("form", "query", "json", "convert")}, opts...)
+	return DTOTo[T](queryValuesMap(v, reflect.TypeFor[T](), dtoOptionsFrom(all)), all...)
 }
 func FromHeaders[T any](h http.Header, opts ...DTOOption) (T, error) {
-	m := map[string]any{}
+	return DTOTo[T](headerValuesMap(h), append([]DTOOption{WithDTOTags("header", "json", "convert")}, opts...)...)
+}
+func headerValuesMap(h map[string][]string) map[string]any {
+	m := make(map[string]any, len(h))
 	for k, v := range h {
 		if len(v) == 1 {
 			m[k] = v[0]
@@ -552,7 +567,7 @@ func FromHeaders[T any](h http.Header, opts ...DTOOption) (T, error) {
 			m[k] = v
 		}
 	}
-	return DTOTo[T](m, append([]DTOOption{WithDTOTags("header", "json", "convert")}, opts...)...)
+	return m
 }
 func FromEnv[T any](opts ...DTOOption) (T, error) {
 	m := map[string]any{}
@@ -572,7 +587,31 @@ func FromCSVRow[T any](headers []string, row []string, opts ...DTOOption) (T, er
 	return DTOTo[T](m, append([]DTOOption{WithDTOTags("csv", "json", "convert")}, opts...)...)
 }
 
+// ToQuery encodes src as query values. Struct fields are named by their query, form, json or
+// convert tag, in that order, and written in their tagged style: slices as repeated keys
+// unless delimited, nested objects in dot notation unless deepObject. Self-encoding values
+// such as time.Time or a TextMarshaler are written as one value.
 func ToQuery(src any, opts ...DTOOption) (url.Values, error) {
+	rv := reflect.ValueOf(src)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		all := append([]DTOOption{WithDTOTags("query", "form", "json", "convert")}, opts...)
+		opt := dtoOptionsFrom(all)
+		q := url.Values{}
+		for _, f := range dtoMetaFor(rv.Type(), opt).fields {
+			fv := fieldByIndex(rv, f.index)
+			if f.writeonly || !fv.IsValid() || !fv.CanInterface() || hasTagOption(f.structField, "omitempty") && fv.IsZero() {
+				continue
+			}
+			style, explode := queryStyle(f.structField)
+			if err := encodeQueryField(q, f.primary, fv, style, explode, opt); err != nil {
+				return nil, err
+			}
+		}
+		return q, nil
+	}
 	m, err := StructToFlatMap(src, opts...)
 	if err != nil {
 		return nil, err
@@ -613,6 +652,10 @@ func ToEnv(src any, opts ...DTOOption) (map[string]string, error) {
 	return out, nil
 }
 
+// Redact returns the fields of src as a map with sensitive values replaced and writeonly
+// fields dropped. Field values keep their Go types, except nested structs, slices and maps
+// whose type holds a sensitive or writeonly field: those become maps and slices so the
+// nested values can be hidden too.
 func Redact(src any, opts ...DTOOption) (map[string]any, error) {
 	o := dtoOptionsFrom(opts)
 	rv := reflect.ValueOf(src)
@@ -635,15 +678,104 @@ func Redact(src any, opts ...DTOOption) (map[string]any, error) {
 		if !fv.IsValid() || !fv.CanInterface() {
 			continue
 		}
-		if f.sensitive {
-			out[f.primary] = "[REDACTED]"
-		} else {
+		switch {
+		case f.sensitive:
+			out[f.primary] = redactedValue
+		case hidesFields(fv.Type(), o, map[reflect.Type]bool{}):
+			out[f.primary] = redactDeep(fv, o, 1)
+		default:
 			out[f.primary] = fv.Interface()
 		}
 	}
 	return out, nil
 }
 
+// hidesFields reports whether values of t contain a sensitive or writeonly struct field.
+func hidesFields(t reflect.Type, o DTOOptions, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if seen[t] || selfEncoding(t) {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Struct:
+		for _, f := range dtoMetaFor(t, o).fields {
+			if f.sensitive || f.writeonly || hidesFields(f.structField.Type, o, seen) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return hidesFields(t.Elem(), o, seen)
+	}
+	return false
+}
+
+// redactDeep converts v to JSON-ready values, replacing sensitive fields and dropping
+// writeonly fields at every level. Types with their own JSON or text encoding are kept as is.
+func redactDeep(v reflect.Value, o DTOOptions, depth int) any {
+	if !v.IsValid() || o.MaxDepth > 0 && depth > o.MaxDepth {
+		return nil
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	if selfEncoding(v.Type()) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		meta := dtoMetaFor(v.Type(), o)
+		out := make(map[string]any, len(meta.fields))
+		for _, f := range meta.fields {
+			fv := fieldByIndex(v, f.index)
+			if f.writeonly || !fv.IsValid() || !fv.CanInterface() {
+				continue
+			}
+			if f.sensitive {
+				out[f.primary] = redactedValue
+				continue
+			}
+			out[f.primary] = redactDeep(fv, o, depth+1)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return v.Interface()
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = redactDeep(v.Index(i), o, depth+1)
+		}
+		return out
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || v.IsNil() {
+			return v.Interface()
+		}
+		out := make(map[string]any, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			out[it.Key().String()] = redactDeep(it.Value(), o, depth+1)
+		}
+		return out
+	}
+	return v.Interface()
+}
+func selfEncoding(t reflect.Type) bool {
+	for _, it := range []reflect.Type{reflect.TypeOf((*json.Marshaler)(nil)).Elem(), reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()} {
+		if t.Implements(it) || reflect.PointerTo(t).Implements(it) {
+			return true
+		}
+	}
+	return false
+}
+
 // DTOStreamJSONL reads JSON lines, converts each record, and writes JSON lines.
 func DTOStreamJSONL[T any](ctx context.Context, r io.Reader, w io.Writer, opts ...DTOOption) error {
 	s := bufio.NewScanner(r)
@@ -698,8 +830,11 @@ func DTOConvertPair[S any, T any](src S, opts ...DTOOption) (T, error) {
 	return DTOTo[T](src, opts...)
 }
 
+// dtoOptionTags are the struct tags whose ",option" suffixes are read by dtoTagOptions.
+var dtoOptionTags = []string{"convert", "json", "env", "query", "form", "header", "csv", "path", "cookie", "db", "xlsx"}
+
 func dtoTagOptions(f reflect.StructField) (transforms []string, readonly, writeonly, sensitive bool) {
-	for _, tag := range []string{"convert", "json", "env", "query", "form", "header", "csv"} {
+	for _, tag := range dtoOptionTags {
 		raw := f.Tag.Get(tag)
 		if raw == "" {
 			continue
@@ -719,7 +854,7 @@ func dtoTagOptions(f reflect.StructField) (transforms []string, readonly, writeo
 				writeonly = true
 			case p == "sensitive" || p == "secret":
 				sensitive = true
-			case strings.HasPrefix(p, "source=") || strings.HasPrefix(p, "alias="):
+			case strings.HasPrefix(p, "source=") || strings.HasPrefix(p, "alias=") || strings.HasPrefix(p, "from="):
 			}
 		}
 	}
@@ -881,7 +1016,7 @@ func parseLeadingIndex(p string) int {
 // Flatten/unflatten-friendly lookup names from convert tag source=/alias= options.
 func dtoExtendedNames(f reflect.StructField) []string {
 	var names []string
-	for _, tag := range []string{"convert", "json", "env", "query", "form", "header", "csv"} {
+	for _, tag := range dtoOptionTags {
 		raw := f.Tag.Get(tag)
 		if raw == "" {
 			continue
@@ -899,6 +1034,21 @@ func dtoExtendedNames(f reflect.StructField) []string {
 	return names
 }
 
+// dtoTagFrom returns the bind sources named by a from=path|query tag option, highest priority first.
+func dtoTagFrom(f reflect.StructField) []string {
+	var from []string
+	for _, tag := range dtoOptionTags {
+		for _, p := range strings.Split(f.Tag.Get(tag), ",")[1:] {
+			if rhs, ok := strings.CutPrefix(strings.TrimSpace(p), "from="); ok {
+				for _, n := range strings.Split(rhs, "|") {
+					from = appendUniqueString(from, strings.TrimSpace(n))
+				}
+			}
+		}
+	}
+	return from
+}
+
 // StableJSONSchema returns an indented JSON representation of SchemaOf[T].
 func StableJSONSchema[T any](opts ...DTOOption) string {
 	b, _ := json.MarshalIndent(SchemaOf[T](opts...), "", "  ")
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 type dtoAdvAddress struct { City string `json:"city"` }
@@ -31,6 +32,30 @@ func TestDTOAdvancedFlattenTransformRedact(t *testing.T) {
 	if red["token"] != "[REDACTED]" { t.Fatalf("token not redacted: %#v", red) }
 }
 
+func TestRedactKeepsNestedTypes(t *testing.T) {
+	type plain struct {
+		Zip string `json:"zip"`
+	}
+	type holder struct {
+		Addr  plain      `json:"addr"`
+		Owner dtoAdvUser `json:"owner"`
+		At    time.Time  `json:"at"`
+	}
+	red, err := Redact(holder{Addr: plain{Zip: "44600"}, Owner: dtoAdvUser{Name: "Ann", Token: "abc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := red["addr"].(plain); !ok {
+		t.Fatalf("plain nested struct lost its type: %#v", red["addr"])
+	}
+	if _, ok := red["at"].(time.Time); !ok {
+		t.Fatalf("time lost its type: %#v", red["at"])
+	}
+	if o, ok := red["owner"].(map[string]any); !ok || o["token"] != "[REDACTED]" {
+		t.Fatalf("nested secret not redacted: %#v", red["owner"])
+	}
+}
+
 func TestDTOReportBatchPatchDiffSchemaAndQuery(t *testing.T) {
 	type User struct { Name string `query:"name"`; Age int `query:"age" default:"18"`; City string `query:"address.city"` }
 	q := url.Values{"name": {"Sujit"}, "address.city": {"Kathmandu"}, "extra": {"x"}}
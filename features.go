@@ -193,11 +193,15 @@ func ToSQLNullTime(v any) sql.NullTime {
 }
 
 func ScanTo[T Target](src any) (T, error) {
+	var z T
 	if src == nil {
-		var z T
 		return z, ErrNil
 	}
-	return To[T](src)
+	v, err := NormalizeDriverValue(src, reflect.TypeFor[T]())
+	if err != nil {
+		return z, err
+	}
+	return To[T](v)
 }
 func ToDriverValue(v any) (driver.Value, error) {
 	switch x := v.(type) {
@@ -205,6 +209,8 @@ func ToDriverValue(v any) (driver.Value, error) {
 		return x, nil
 	case driver.Valuer:
 		return x.Value()
+	case driverValuer:
+		return x.driverValue()
 	case int:
 		return int64(x), nil
 	case int8:
@@ -1065,6 +1071,36 @@ const (
 	KindStruct
 )
 
+// kindName names k in problem details. Kind has no String method so %v keeps printing numbers.
+func kindName(k Kind) string {
+	switch k {
+	case KindBool:
+		return "bool"
+	case KindString:
+		return "string"
+	case KindBytes:
+		return "bytes"
+	case KindInt:
+		return "int"
+	case KindUint:
+		return "uint"
+	case KindFloat:
+		return "float"
+	case KindTime:
+		return "time"
+	case KindDuration:
+		return "duration"
+	case KindSlice:
+		return "slice"
+	case KindMap:
+		return "map"
+	case KindStruct:
+		return "struct"
+	default:
+		return "invalid"
+	}
+}
+
 func KindOf(v any) Kind {
 	switch v.(type) {
 	case bool:
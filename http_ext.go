@@ -0,0 +1,833 @@
+package convert
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnsupportedMediaType = errors.New("convert: unsupported media type")
+	ErrMalformedBody        = errors.New("convert: malformed request body")
+	ErrTooLarge             = errors.New("convert: request body too large")
+)
+
+// RequestError is a request binding failure carrying the HTTP status it maps to:
+// 415 for an unsupported Content-Type and 400 for a body that does not decode.
+type RequestError struct {
+	Status    int
+	MediaType string
+	Err       error
+}
+
+func (e *RequestError) Error() string {
+	if e.MediaType == "" {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + " (" + e.MediaType + ")"
+}
+func (e *RequestError) Unwrap() error { return e.Err }
+
+// BodyDecoder decodes a request body of one media type. params holds the Content-Type parameters.
+type BodyDecoder func(body io.Reader, params map[string]string) (any, error)
+
+var bodyDecoders sync.Map
+
+// RegisterBodyDecoder installs fn for mediaType, e.g. "application/cbor". A structured syntax
+// suffix such as "+cbor" matches every "application/*+cbor" type. Registered decoders take
+// precedence over the built-in JSON, form, multipart and XML decoders.
+func RegisterBodyDecoder(mediaType string, fn BodyDecoder) {
+	if fn != nil {
+		bodyDecoders.Store(strings.ToLower(mediaType), fn)
+	}
+}
+func UnregisterBodyDecoder(mediaType string) { bodyDecoders.Delete(strings.ToLower(mediaType)) }
+
+// DecodeBody decodes r.Body according to its Content-Type. A body without a Content-Type is
+// treated as JSON, and an empty body decodes to nil. JSON is decoded within the default DTO
+// MaxDepth, MaxSliceLen and MaxMapSize limits.
+func DecodeBody(r *http.Request) (any, error) {
+	return decodeBody(r, HTTPBindOptions{}, dtoOptionsFrom(nil))
+}
+func decodeBody(r *http.Request, o HTTPBindOptions, opt DTOOptions) (any, error) {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil, nil
+	}
+	mt, params := "application/json", map[string]string{}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		var err error
+		if mt, params, err = mime.ParseMediaType(ct); err != nil {
+			return nil, &RequestError{Status: http.StatusUnsupportedMediaType, MediaType: ct, Err: errf("%w: %w", ErrUnsupportedMediaType, err)}
+		}
+	}
+	dec, ok := bodyDecoderFor(mt, jsonLimitsFrom(o, opt))
+	if !ok {
+		return nil, &RequestError{Status: http.StatusUnsupportedMediaType, MediaType: mt, Err: ErrUnsupportedMediaType}
+	}
+	v, err := readBody(r, mt, params, dec, o)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	return v, err
+}
+
+// readBody runs dec over r.Body capped at o.MaxBodyBytes and maps failures to a *RequestError.
+func readBody(r *http.Request, mt string, params map[string]string, dec BodyDecoder, o HTTPBindOptions) (any, error) {
+	if o.MaxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, o.MaxBodyBytes)
+	}
+	v, err := dec(r.Body, params)
+	if err == nil {
+		return v, nil
+	}
+	var re *RequestError
+	var mbe *http.MaxBytesError
+	switch {
+	case err == io.EOF:
+		return nil, err
+	case errors.As(err, &re):
+		return nil, err
+	case errors.As(err, &mbe):
+		return nil, &RequestError{Status: http.StatusRequestEntityTooLarge, MediaType: mt, Err: errf("%w: limit is %d bytes", ErrTooLarge, mbe.Limit)}
+	}
+	return nil, &RequestError{Status: http.StatusBadRequest, MediaType: mt, Err: errf("%w: %w", ErrMalformedBody, err)}
+}
+
+func bodyDecoderFor(mt string, l jsonLimits) (BodyDecoder, bool) {
+	if v, ok := bodyDecoders.Load(mt); ok {
+		return v.(BodyDecoder), true
+	}
+	suffix := ""
+	if i := strings.LastIndexByte(mt, '+'); i >= 0 {
+		suffix = mt[i:]
+		if v, ok := bodyDecoders.Load(suffix); ok {
+			return v.(BodyDecoder), true
+		}
+	}
+	switch {
+	case mt == "application/json" || suffix == "+json":
+		return func(body io.Reader, _ map[string]string) (any, error) { return decodeJSONLimited(body, l) }, true
+	case mt == "application/x-www-form-urlencoded":
+		return decodeFormBody, true
+	case mt == "multipart/form-data":
+		return decodeMultipartBody, true
+	case mt == "application/xml" || mt == "text/xml" || suffix == "+xml":
+		return decodeXMLBody, true
+	}
+	return nil, false
+}
+
+func decodeFormBody(body io.Reader, _ map[string]string) (any, error) {
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	v, err := url.ParseQuery(string(b))
+	if err != nil {
+		return nil, err
+	}
+	return valuesToMap(v), nil
+}
+
+// decodeMultipartBody returns the text parts of a multipart body; file parts are skipped.
+func decodeMultipartBody(body io.Reader, params map[string]string) (any, error) {
+	if params["boundary"] == "" {
+		return nil, errors.New("missing multipart boundary")
+	}
+	mr := multipart.NewReader(body, params["boundary"])
+	values := url.Values{}
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return valuesToMap(values), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p.FileName() != "" || p.FormName() == "" {
+			continue
+		}
+		b, err := io.ReadAll(p)
+		if err != nil {
+			return nil, err
+		}
+		values.Add(p.FormName(), string(b))
+	}
+}
+
+// decodeXMLBody maps the root element's attributes and children to a map. Repeated child
+// elements become slices and leaf elements become their trimmed text.
+func decodeXMLBody(body io.Reader, _ map[string]string) (any, error) {
+	d := xml.NewDecoder(body)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return xmlElementValue(d, start)
+		}
+	}
+}
+func xmlElementValue(d *xml.Decoder, start xml.StartElement) (any, error) {
+	m := map[string]any{}
+	for _, a := range start.Attr {
+		m[a.Name.Local] = a.Value
+	}
+	repeated := map[string]bool{}
+	var text strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			v, err := xmlElementValue(d, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			prev, exists := m[name]
+			switch {
+			case !exists:
+				m[name] = v
+			case repeated[name]:
+				m[name] = append(prev.([]any), v)
+			default:
+				m[name], repeated[name] = []any{prev, v}, true
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if len(m) == 0 {
+				return strings.TrimSpace(text.String()), nil
+			}
+			return m, nil
+		}
+	}
+}
+
+// MultipartLimits bounds BindMultipart. Zero fields use the DefaultMultipartLimits value.
+type MultipartLimits struct {
+	MaxMemory    int64 // file bytes held in memory; larger uploads spill to temp files
+	MaxFileSize  int64 // per file part
+	MaxTotalSize int64 // whole request body
+}
+
+func DefaultMultipartLimits() MultipartLimits {
+	return MultipartLimits{MaxMemory: 32 << 20, MaxFileSize: 10 << 20, MaxTotalSize: 64 << 20}
+}
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	readCloserType  = reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+)
+
+// BindMultipart binds a multipart/form-data request into dst. Text parts go through the DTO
+// pipeline with Form() tags; file parts bind to *multipart.FileHeader, []*multipart.FileHeader
+// or io.ReadCloser fields by form tag. A bound io.ReadCloser must be closed by the caller.
+// Temp files are tracked in r.MultipartForm and removed by net/http after the handler returns.
+// Size violations are *RequestError values with status 413.
+func BindMultipart(r *http.Request, dst any, limits MultipartLimits, opts ...DTOOption) error {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt != "multipart/form-data" {
+		return &RequestError{Status: http.StatusUnsupportedMediaType, MediaType: mt, Err: ErrUnsupportedMediaType}
+	}
+	src, err := parseMultipart(r, mt, limits)
+	if err != nil {
+		return err
+	}
+	return DTO(dst, src, append(append([]DTOOption{Form()}, opts...), withMultipartFiles())...)
+}
+
+// parseMultipart parses r into text values and file headers, enforcing limits. Zero limits
+// use the DefaultMultipartLimits value.
+func parseMultipart(r *http.Request, mt string, limits MultipartLimits) (map[string]any, error) {
+	d := DefaultMultipartLimits()
+	if limits.MaxMemory <= 0 {
+		limits.MaxMemory = d.MaxMemory
+	}
+	if limits.MaxFileSize <= 0 {
+		limits.MaxFileSize = d.MaxFileSize
+	}
+	if limits.MaxTotalSize <= 0 {
+		limits.MaxTotalSize = d.MaxTotalSize
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, limits.MaxTotalSize)
+	if err := r.ParseMultipartForm(limits.MaxMemory); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return nil, &RequestError{Status: http.StatusRequestEntityTooLarge, MediaType: mt, Err: errf("%w: %w", ErrTooLarge, err)}
+		}
+		return nil, &RequestError{Status: http.StatusBadRequest, MediaType: mt, Err: errf("%w: %w", ErrMalformedBody, err)}
+	}
+	src := valuesToMap(r.MultipartForm.Value)
+	for name, files := range r.MultipartForm.File {
+		for _, fh := range files {
+			if fh.Size > limits.MaxFileSize {
+				return nil, &RequestError{Status: http.StatusRequestEntityTooLarge, MediaType: mt, Err: PathError(name, KindString, KindInvalid, fh.Filename, errf("%w: file exceeds %d bytes", ErrTooLarge, limits.MaxFileSize))}
+			}
+		}
+		src[name] = files
+	}
+	return src, nil
+}
+
+// withMultipartFiles wraps any configured DecodeHook so file parts bind to file-typed fields.
+func withMultipartFiles() DTOOption {
+	return func(o *DTOOptions) {
+		next := o.DecodeHook
+		o.DecodeHook = func(ctx DTOContext, dst reflect.Value, src any) (bool, error) {
+			if files, ok := src.([]*multipart.FileHeader); ok {
+				return bindFileField(dst, files)
+			}
+			if next != nil {
+				return next(ctx, dst, src)
+			}
+			return false, nil
+		}
+	}
+}
+func bindFileField(dst reflect.Value, files []*multipart.FileHeader) (bool, error) {
+	switch dst.Type() {
+	case fileHeadersType:
+		dst.Set(reflect.ValueOf(files))
+		return true, nil
+	case fileHeaderType:
+		if len(files) > 0 {
+			dst.Set(reflect.ValueOf(files[0]))
+		}
+		return true, nil
+	case readCloserType:
+		if len(files) == 0 {
+			return true, nil
+		}
+		f, err := files[0].Open()
+		if err != nil {
+			return true, err
+		}
+		dst.Set(reflect.ValueOf(io.ReadCloser(f)))
+		return true, nil
+	}
+	return false, nil
+}
+
+// validateFileRule applies the file rules maxsize=<size> and mime=<type>|<type/*> to
+// *multipart.FileHeader and []*multipart.FileHeader fields. Other values pass.
+func validateFileRule(v reflect.Value, name, param, path string) error {
+	var files []*multipart.FileHeader
+	switch x := v.Interface().(type) {
+	case *multipart.FileHeader:
+		if x != nil {
+			files = []*multipart.FileHeader{x}
+		}
+	case []*multipart.FileHeader:
+		files = x
+	default:
+		return nil
+	}
+	for _, fh := range files {
+		var err error
+		switch name {
+		case "maxsize":
+			limit, perr := ToBytesSize(param)
+			if perr != nil {
+				err = errf("%w: invalid maxsize %q", ErrValidation, param)
+			} else if fh.Size < 0 || uint64(fh.Size) > limit {
+				err = errf("%w: file is %d bytes, limit is %s", ErrValidation, fh.Size, param)
+			}
+		case "mime":
+			if ct := fh.Header.Get("Content-Type"); !mimeAllowed(ct, strings.Split(param, "|")) {
+				err = errf("%w: content type %q is not allowed", ErrValidation, ct)
+			}
+		}
+		if err != nil {
+			return PathError(path, KindString, KindString, fh.Filename, err)
+		}
+	}
+	return nil
+}
+func mimeAllowed(ct string, allowed []string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == mt || strings.HasSuffix(a, "/*") && strings.HasPrefix(mt, a[:len(a)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// PathValuer returns a named path parameter. *http.Request implements it for Go 1.22
+// ServeMux patterns; other routers are adapted with PathValuerFunc and WithPathValues.
+type PathValuer interface {
+	PathValue(name string) string
+}
+
+type PathValuerFunc func(name string) string
+
+func (f PathValuerFunc) PathValue(name string) string { return f(name) }
+
+type pathValuesKey struct{}
+
+// WithPathValues returns a shallow copy of r whose path parameters are read from p, e.g.
+// WithPathValues(r, PathValuerFunc(func(n string) string { return chi.URLParam(r, n) })).
+func WithPathValues(r *http.Request, p PathValuer) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), pathValuesKey{}, p))
+}
+func requestPathValuer(r *http.Request) PathValuer {
+	if p, ok := r.Context().Value(pathValuesKey{}).(PathValuer); ok {
+		return p
+	}
+	return r
+}
+
+// FromPathSource reads the named path parameters. Path values cannot be enumerated, so the
+// caller lists the names to look up; empty values are skipped.
+func FromPathSource(p PathValuer, names ...string) BindSource {
+	m := map[string]any{}
+	for _, n := range names {
+		if v := p.PathValue(n); v != "" {
+			m[n] = v
+		}
+	}
+	return BindSource{Name: "path", Data: m, Tags: []string{"path", "json", "convert"}}
+}
+
+// FromCookieSource reads request cookies. When a name repeats, the first cookie wins.
+func FromCookieSource(r *http.Request) BindSource {
+	m := map[string]any{}
+	for _, c := range r.Cookies() {
+		if _, ok := m[c.Name]; !ok {
+			m[c.Name] = c.Value
+		}
+	}
+	return BindSource{Name: "cookie", Data: m, Tags: []string{"cookie", "json", "convert"}}
+}
+
+func BindPath(r *http.Request, dst any, opts ...DTOOption) error {
+	all := append([]DTOOption{WithDTOTags("path", "json", "convert")}, opts...)
+	src := FromPathSource(requestPathValuer(r), dtoFieldNames(reflect.TypeOf(dst), dtoOptionsFrom(all))...)
+	return DTO(dst, src.Data, all...)
+}
+func BindCookies(r *http.Request, dst any, opts ...DTOOption) error {
+	return DTO(dst, FromCookieSource(r).Data, append([]DTOOption{WithDTOTags("cookie", "json", "convert")}, opts...)...)
+}
+
+// dtoFieldNames lists the lookup names of t's top-level fields.
+func dtoFieldNames(t reflect.Type, opt DTOOptions) []string {
+	t = indirectType(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for _, f := range dtoMetaFor(t, opt).fields {
+		for _, n := range f.names {
+			names = appendUniqueString(names, n)
+		}
+	}
+	return names
+}
+
+// HTTPBindOptions bounds request decoding. Limits are enforced while the body is read, before
+// the payload is fully materialised. Zero or negative values disable a limit.
+type HTTPBindOptions struct {
+	MaxBodyBytes          int64 // enforced with http.MaxBytesReader; 413 when exceeded
+	MaxStringLen          int   // longest JSON string or object key
+	DisallowUnknownFields bool  // reject body keys no field consumes
+	Multipart             MultipartLimits
+	// DTOOptions configure binding. Their MaxDepth, MaxSliceLen and MaxMapSize also bound
+	// JSON nesting, array length and object size during decoding.
+	DTOOptions []DTOOption
+}
+
+type HTTPBindOption func(*HTTPBindOptions)
+
+// DefaultHTTPBindOptions caps bodies at 10 MiB and JSON strings at 1 MiB.
+func DefaultHTTPBindOptions() HTTPBindOptions {
+	return HTTPBindOptions{MaxBodyBytes: 10 << 20, MaxStringLen: 1 << 20, Multipart: DefaultMultipartLimits()}
+}
+
+func WithMaxBodyBytes(n int64) HTTPBindOption { return func(o *HTTPBindOptions) { o.MaxBodyBytes = n } }
+func WithMaxStringLen(n int) HTTPBindOption   { return func(o *HTTPBindOptions) { o.MaxStringLen = n } }
+func WithDisallowUnknownFields() HTTPBindOption {
+	return func(o *HTTPBindOptions) { o.DisallowUnknownFields = true }
+}
+func WithMultipartLimits(l MultipartLimits) HTTPBindOption {
+	return func(o *HTTPBindOptions) { o.Multipart = l }
+}
+func WithBindDTOOptions(opts ...DTOOption) HTTPBindOption {
+	return func(o *HTTPBindOptions) { o.DTOOptions = append(o.DTOOptions, opts...) }
+}
+
+// withoutBindLimits lifts the body and string caps for BindJSON and BindRequest, which
+// predate them; use the With variants to enforce DefaultHTTPBindOptions.
+func withoutBindLimits(o *HTTPBindOptions) { o.MaxBodyBytes, o.MaxStringLen = 0, 0 }
+
+func httpBindOptionsFrom(opts []HTTPBindOption) HTTPBindOptions {
+	o := DefaultHTTPBindOptions()
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// BindRequestWith is BindRequest with decoding limits. Multipart bodies bind files as
+// BindMultipart does, within o.Multipart.
+func BindRequestWith(r *http.Request, dst any, opts ...HTTPBindOption) error {
+	src, all, err := requestBindInput(r, reflect.TypeOf(dst), httpBindOptionsFrom(opts))
+	if err != nil {
+		return err
+	}
+	return DTO(dst, src, all...)
+}
+
+// requestBindInput decodes and merges every request source for t and returns the DTO options
+// to bind it with.
+func requestBindInput(r *http.Request, t reflect.Type, o HTTPBindOptions) (map[string]any, []DTOOption, error) {
+	all := append([]DTOOption{WithDTOFlatten()}, o.DTOOptions...)
+	opt := dtoOptionsFrom(all)
+	ct := r.Header.Get("Content-Type")
+	body := map[string]any{}
+	if mt, _, _ := mime.ParseMediaType(ct); mt == "multipart/form-data" {
+		m, err := parseMultipart(r, mt, o.Multipart)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, all = m, append(all, withMultipartFiles())
+	} else {
+		decoded, err := decodeBody(r, o, opt)
+		if err != nil {
+			return nil, nil, err
+		}
+		if decoded != nil {
+			m, ok := decoded.(map[string]any)
+			if !ok {
+				return nil, nil, &RequestError{Status: http.StatusBadRequest, MediaType: ct, Err: errf("%w: body must be an object", ErrMalformedBody)}
+			}
+			body = m
+		}
+	}
+	if err := checkUnknownBodyKeys(body, t, ct, o, opt); err != nil {
+		return nil, nil, err
+	}
+	sources := []BindSource{
+		requestCookieSource(r, t, opt),
+		FromMapSource("body", body),
+		{Name: "query", Data: queryValuesMap(r.URL.Query(), t, opt), Tags: []string{"query", "form", "json", "convert"}},
+		FromHeaderSource(r.Header),
+		FromPathSource(requestPathValuer(r), dtoFieldNames(t, opt)...),
+	}
+	return mergeBindSources(t, opt, sources), all, nil
+}
+
+// requestCookieSource keeps only the cookies a field of t opts into with a cookie tag or
+// from=cookie, so the Cookie header cannot set arbitrary fields.
+func requestCookieSource(r *http.Request, t reflect.Type, opt DTOOptions) BindSource {
+	src := FromCookieSource(r)
+	allowed := map[string]bool{}
+	if t = indirectType(t); t != nil && t.Kind() == reflect.Struct {
+		for _, f := range dtoMetaFor(t, opt).fields {
+			if n := tagName(f.structField.Tag.Get("cookie")); n != "" {
+				allowed[n] = true
+			}
+			if slices.Contains(f.from, "cookie") {
+				for _, n := range f.names {
+					allowed[n] = true
+				}
+			}
+		}
+	}
+	for k := range src.Data {
+		if !allowed[k] {
+			delete(src.Data, k)
+		}
+	}
+	return src
+}
+
+// BindJSONWith decodes the body as JSON regardless of Content-Type, within the limits of o.
+func BindJSONWith(r *http.Request, dst any, opts ...HTTPBindOption) error {
+	defer r.Body.Close()
+	o := httpBindOptionsFrom(opts)
+	all := append([]DTOOption{WithDTOTags("json", "convert")}, o.DTOOptions...)
+	opt := dtoOptionsFrom(all)
+	dec, _ := bodyDecoderFor("application/json", jsonLimitsFrom(o, opt))
+	v, err := readBody(r, "application/json", nil, dec, o)
+	if err != nil {
+		if err == io.EOF {
+			err = &RequestError{Status: http.StatusBadRequest, MediaType: "application/json", Err: errf("%w: empty body", ErrMalformedBody)}
+		}
+		return err
+	}
+	if m, ok := v.(map[string]any); ok {
+		if err := checkUnknownBodyKeys(m, reflect.TypeOf(dst), "application/json", o, opt); err != nil {
+			return err
+		}
+	}
+	return DTO(dst, v, all...)
+}
+
+// checkUnknownBodyKeys applies DisallowUnknownFields with the config loader's key checker.
+func checkUnknownBodyKeys(body map[string]any, t reflect.Type, mt string, o HTTPBindOptions, opt DTOOptions) error {
+	if !o.DisallowUnknownFields || len(body) == 0 {
+		return nil
+	}
+	errs := checkConfigKeys(body, t, "", "body", func(k string) string { return k }, opt, 0)
+	if len(errs) == 0 {
+		return nil
+	}
+	return &RequestError{Status: http.StatusBadRequest, MediaType: mt, Err: MultiError{Errors: errs}}
+}
+
+type jsonLimits struct{ depth, array, object, str int }
+
+func jsonLimitsFrom(o HTTPBindOptions, opt DTOOptions) jsonLimits {
+	return jsonLimits{depth: opt.MaxDepth, array: opt.MaxSliceLen, object: opt.MaxMapSize, str: o.MaxStringLen}
+}
+
+// decodeJSONLimited decodes one JSON value token by token, failing as soon as a limit is
+// exceeded or data follows the value. Numbers decode to float64 as with encoding/json.
+func decodeJSONLimited(body io.Reader, l jsonLimits) (any, error) {
+	d := json.NewDecoder(body)
+	v, err := readJSONValue(d, l, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("data after the JSON value")
+		}
+		return nil, err
+	}
+	return v, nil
+}
+func readJSONValue(d *json.Decoder, l jsonLimits, path string, depth int) (any, error) {
+	tok, err := jsonToken(d, depth)
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case string:
+		if l.str > 0 && len(t) > l.str {
+			return nil, PathError(path, KindString, KindString, nil, errf("%w: string longer than %d bytes", ErrOverflow, l.str))
+		}
+		return t, nil
+	case json.Delim:
+		if l.depth > 0 && depth > l.depth {
+			return nil, PathError(path, KindInvalid, KindInvalid, nil, errf("%w: nesting deeper than %d", ErrOverflow, l.depth))
+		}
+		if t == '[' {
+			items := []any{}
+			for d.More() {
+				if l.array >= 0 && len(items) >= l.array {
+					return nil, PathError(path, KindSlice, KindSlice, nil, errf("%w: more than %d items", ErrOverflow, l.array))
+				}
+				v, err := readJSONValue(d, l, indexPath(path, len(items)), depth+1)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, v)
+			}
+			_, err := jsonToken(d, depth+1)
+			return items, err
+		}
+		m := map[string]any{}
+		for d.More() {
+			kt, err := jsonToken(d, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			k, _ := kt.(string)
+			if l.str > 0 && len(k) > l.str {
+				return nil, PathError(path, KindString, KindString, nil, errf("%w: key longer than %d bytes", ErrOverflow, l.str))
+			}
+			if l.object >= 0 && len(m) >= l.object {
+				return nil, PathError(path, KindMap, KindMap, nil, errf("%w: more than %d keys", ErrOverflow, l.object))
+			}
+			v, err := readJSONValue(d, l, mapPath(path, k), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		_, err := jsonToken(d, depth+1)
+		return m, err
+	}
+	return tok, nil
+}
+
+// jsonToken reads the next token; io.EOF only means an empty body at depth 0.
+func jsonToken(d *json.Decoder, depth int) (json.Token, error) {
+	tok, err := d.Token()
+	if err == io.EOF && depth > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return tok, err
+}
+
+// Handler adapts fn to http.Handler. The request is bound with BindRequestWith semantics and
+// every field error is collected; failures and errors from fn are written with WriteProblem.
+// The response is encoded as JSON after deep redaction, so sensitive and writeonly fields
+// never leave the server.
+func Handler[Req, Resp any](fn func(context.Context, Req) (Resp, error), opts ...HTTPBindOption) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		o := httpBindOptionsFrom(opts)
+		src, all, err := requestBindInput(r, reflect.TypeOf(&req), o)
+		if err == nil {
+			if errs := collectDTOErrors(reflect.ValueOf(&req).Elem(), src, "", dtoOptionsFrom(all)); len(errs) > 0 {
+				err = MultiError{Errors: errs}
+			}
+		}
+		if err != nil {
+			WriteProblem(w, err)
+			return
+		}
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			WriteProblem(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(redactDeep(reflect.ValueOf(resp), dtoOptionsFrom(o.DTOOptions), 0))
+	})
+}
+
+// Problem is an RFC 9457 problem details body. Errors is an extension member listing each
+// field failure.
+type Problem struct {
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Status int            `json:"status"`
+	Detail string         `json:"detail,omitempty"`
+	Errors []ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError describes one failing field. Value is redacted for sensitive fields and
+// resolved secrets.
+type ProblemError struct {
+	Path   string `json:"path,omitempty"`
+	Code   string `json:"code,omitempty"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Value  any    `json:"value,omitempty"`
+	Detail string `json:"detail"`
+}
+
+type problemType struct {
+	status int
+	uri    string
+}
+
+var problemTypes sync.Map
+
+// RegisterProblemType overrides the HTTP status and type URI used for code.
+func RegisterProblemType(code Code, status int, typeURI string) {
+	problemTypes.Store(code, problemType{status: status, uri: typeURI})
+}
+
+func problemTypeFor(c Code) problemType {
+	if v, ok := problemTypes.Load(c); ok {
+		return v.(problemType)
+	}
+	status := http.StatusBadRequest
+	switch c {
+	case CodeEmpty, CodePrecisionLoss, CodeValidationFailed:
+		status = http.StatusUnprocessableEntity
+	}
+	return problemType{status: status, uri: "urn:convert:problem:" + c.String()}
+}
+
+// ProblemDetails maps err to a Problem. Conversion and validation errors use the status and
+// type of their Code, the first failure deciding for a MultiError. A *RequestError keeps its
+// status. Any other error is a 500 with no detail, so internal messages are not exposed.
+func ProblemDetails(err error) Problem {
+	if err == nil {
+		return Problem{}
+	}
+	var me MultiError
+	var d *ErrorDetail
+	var re *RequestError
+	var p Problem
+	switch {
+	case errors.As(err, &me) && len(me.Errors) > 0:
+		p = ProblemDetails(me.Errors[0])
+		p.Errors = nil
+		for _, e := range me.Errors {
+			p.Errors = append(p.Errors, problemErrorFor(e))
+		}
+		if len(me.Errors) > 1 {
+			p.Detail = strconv.Itoa(len(me.Errors)) + " fields are invalid."
+		}
+	case errors.As(err, &d):
+		pt := problemTypeFor(d.Code)
+		p = Problem{Type: pt.uri, Status: pt.status, Detail: HumanError(d), Errors: []ProblemError{problemErrorFor(d)}}
+	default:
+		p = Problem{Type: "about:blank", Status: http.StatusInternalServerError}
+	}
+	if errors.As(err, &re) {
+		p.Status = re.Status
+		if p.Type == "about:blank" {
+			p.Type = "urn:convert:problem:" + requestProblemName(re)
+			p.Detail = re.Error()
+		}
+	}
+	p.Title = http.StatusText(p.Status)
+	return p
+}
+func problemErrorFor(err error) ProblemError {
+	var d *ErrorDetail
+	if !errors.As(err, &d) {
+		return ProblemError{Detail: err.Error()}
+	}
+	pe := ProblemError{Path: d.Path, Code: d.Code.String(), Value: redactValue(d.Value, d.Sensitive), Detail: codeString(d.Code)}
+	if d.From != KindInvalid {
+		pe.From = kindName(d.From)
+	}
+	if d.To != KindInvalid {
+		pe.To = kindName(d.To)
+	}
+	if d.Cause != nil {
+		pe.Detail = d.Cause.Error()
+	}
+	return pe
+}
+func requestProblemName(re *RequestError) string {
+	switch {
+	case errors.Is(re, ErrUnsupportedMediaType):
+		return "unsupported_media_type"
+	case errors.Is(re, ErrTooLarge):
+		return "too_large"
+	}
+	return "malformed_body"
+}
+
+// WriteProblem writes ProblemDetails(err) as application/problem+json.
+func WriteProblem(w http.ResponseWriter, err error) {
+	p := ProblemDetails(err)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,349 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bodyUser struct {
+	ID   int      `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func TestBindRequestContentTypes(t *testing.T) {
+	var mp bytes.Buffer
+	w := multipart.NewWriter(&mp)
+	_ = w.WriteField("id", "3")
+	_ = w.WriteField("name", "Cy")
+	fw, _ := w.CreateFormFile("avatar", "a.png")
+	_, _ = fw.Write([]byte("png"))
+	_ = w.Close()
+	cases := []struct {
+		ct, body string
+		want     bodyUser
+	}{
+		{"", `{"id":1,"name":"Ann"}`, bodyUser{ID: 1, Name: "Ann"}},
+		{"application/problem+json", `{"id":1}`, bodyUser{ID: 1}},
+		{"application/x-www-form-urlencoded", "id=2&name=Bo&tags=a&tags=b", bodyUser{ID: 2, Name: "Bo", Tags: []string{"a", "b"}}},
+		{w.FormDataContentType(), mp.String(), bodyUser{ID: 3, Name: "Cy"}},
+		{"application/xml; charset=utf-8", `<user id="4"><name>Di</name><tags>x</tags><tags>y</tags></user>`, bodyUser{ID: 4, Name: "Di", Tags: []string{"x", "y"}}},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(c.body))
+		if c.ct != "" {
+			r.Header.Set("Content-Type", c.ct)
+		}
+		var got bodyUser
+		if err := BindRequest(r, &got); err != nil || got.ID != c.want.ID || got.Name != c.want.Name || strings.Join(got.Tags, ",") != strings.Join(c.want.Tags, ",") {
+			t.Fatalf("%s: got %+v err=%v", c.ct, got, err)
+		}
+	}
+}
+
+func TestBindRequestBodyErrors(t *testing.T) {
+	var re *RequestError
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":`))
+	r.Header.Set("Content-Type", "application/json")
+	err := BindRequest(r, &bodyUser{})
+	if !errors.As(err, &re) || re.Status != http.StatusBadRequest || !errors.Is(err, ErrMalformedBody) {
+		t.Fatalf("malformed json: %v", err)
+	}
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("x"))
+	r.Header.Set("Content-Type", "application/msgpack")
+	err = BindRequest(r, &bodyUser{})
+	if !errors.As(err, &re) || re.Status != http.StatusUnsupportedMediaType || !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Fatalf("unsupported: %v", err)
+	}
+	RegisterBodyDecoder("application/msgpack", func(body io.Reader, _ map[string]string) (any, error) {
+		b, err := io.ReadAll(body)
+		return map[string]any{"name": string(b)}, err
+	})
+	defer UnregisterBodyDecoder("application/msgpack")
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("packed"))
+	r.Header.Set("Content-Type", "application/msgpack")
+	var got bodyUser
+	if err := BindRequest(r, &got); err != nil || got.Name != "packed" {
+		t.Fatalf("registered decoder: %+v %v", got, err)
+	}
+	r = httptest.NewRequest(http.MethodGet, "/?id=9", nil)
+	if err := BindRequest(r, &got); err != nil || got.ID != 9 {
+		t.Fatalf("empty body: %+v %v", got, err)
+	}
+}
+
+type uploadForm struct {
+	Title   string                  `form:"title" validate:"required"`
+	Avatar  *multipart.FileHeader   `form:"avatar" validate:"required,maxsize=1KiB,mime=image/png|image/jpeg"`
+	Docs    []*multipart.FileHeader `form:"docs" validate:"mime=text/*"`
+	Payload io.ReadCloser           `form:"payload"`
+}
+
+func multipartRequest(t *testing.T, avatarType string, avatar []byte) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	_ = w.WriteField("title", "hello")
+	part := func(field, name, ct string, data []byte) {
+		h := make(map[string][]string)
+		h["Content-Disposition"] = []string{`form-data; name="` + field + `"; filename="` + name + `"`}
+		h["Content-Type"] = []string{ct}
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = pw.Write(data)
+	}
+	part("avatar", "a.png", avatarType, avatar)
+	part("docs", "a.txt", "text/plain", []byte("a"))
+	part("docs", "b.csv", "text/csv", []byte("b"))
+	part("payload", "p.bin", "application/octet-stream", []byte("payload"))
+	_ = w.Close()
+	r := httptest.NewRequest(http.MethodPost, "/upload", &buf)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	return r
+}
+
+func TestBindMultipart(t *testing.T) {
+	var f uploadForm
+	r := multipartRequest(t, "image/png", []byte("png"))
+	if err := BindRequestForm(r, &f); err != nil {
+		t.Fatal(err)
+	}
+	defer r.MultipartForm.RemoveAll()
+	if f.Title != "hello" || f.Avatar == nil || f.Avatar.Filename != "a.png" || len(f.Docs) != 2 || f.Payload == nil {
+		t.Fatalf("unexpected bind: %+v", f)
+	}
+	b, _ := io.ReadAll(f.Payload)
+	f.Payload.Close()
+	if string(b) != "payload" {
+		t.Fatalf("payload %q", b)
+	}
+
+	err := BindMultipart(multipartRequest(t, "application/pdf", []byte("pdf")), &uploadForm{}, DefaultMultipartLimits())
+	if !IsValidation(err) || !strings.Contains(err.Error(), "application/pdf") {
+		t.Fatalf("mime allowlist: %v", err)
+	}
+	err = BindMultipart(multipartRequest(t, "image/png", bytes.Repeat([]byte("x"), 2048)), &uploadForm{}, DefaultMultipartLimits())
+	if !IsValidation(err) {
+		t.Fatalf("maxsize rule: %v", err)
+	}
+
+	var re *RequestError
+	err = BindMultipart(multipartRequest(t, "image/png", bytes.Repeat([]byte("x"), 4096)), &uploadForm{}, MultipartLimits{MaxFileSize: 1024})
+	if !errors.As(err, &re) || re.Status != http.StatusRequestEntityTooLarge || !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("per-file limit: %v", err)
+	}
+	err = BindMultipart(multipartRequest(t, "image/png", bytes.Repeat([]byte("x"), 4096)), &uploadForm{}, MultipartLimits{MaxTotalSize: 1024})
+	if !errors.As(err, &re) || re.Status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("total limit: %v", err)
+	}
+}
+
+func TestBindPathAndCookies(t *testing.T) {
+	type updateUser struct {
+		ID      int    `json:"id" path:"id"`
+		OrgID   int    `json:"org_id,from=path"`
+		Name    string `json:"name"`
+		Session string `cookie:"session"`
+		Page    int    `json:"page,from=query|body"`
+	}
+	var got updateUser
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /orgs/{org_id}/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if err := BindRequest(r, &got); err != nil {
+			t.Fatal(err)
+		}
+	})
+	r := httptest.NewRequest(http.MethodPut, "/orgs/7/users/42?page=3", strings.NewReader(`{"id":1,"org_id":99,"name":"Ann","page":9}`))
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	r.AddCookie(&http.Cookie{Name: "name", Value: "Mallory"})
+	mux.ServeHTTP(httptest.NewRecorder(), r)
+	if got.ID != 42 || got.OrgID != 7 || got.Name != "Ann" || got.Session != "abc" || got.Page != 3 {
+		t.Fatalf("unexpected bind: %+v", got)
+	}
+
+	got = updateUser{}
+	r = httptest.NewRequest(http.MethodPut, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "name", Value: "Mallory"})
+	if err := BindRequest(r, &got); err != nil || got.Name != "" {
+		t.Fatalf("untagged field bound from cookie: %+v %v", got, err)
+	}
+
+	got = updateUser{}
+	r = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"org_id":99,"page":9}`))
+	if err := BindRequest(r, &got); err != nil || got.OrgID != 0 || got.Page != 9 {
+		t.Fatalf("from= must ignore other sources: %+v %v", got, err)
+	}
+
+	type tenantReq struct {
+		Tenant string `header:"X-Tenant-ID"`
+	}
+	var tr tenantReq
+	r = httptest.NewRequest(http.MethodGet, "/?X-Tenant-ID=evilq", nil)
+	r.Header.Set("X-Tenant-ID", "good")
+	if err := BindRequest(r, &tr); err != nil || tr.Tenant != "good" {
+		t.Fatalf("query overrode header: %+v %v", tr, err)
+	}
+	tr = tenantReq{}
+	r = httptest.NewRequest(http.MethodPost, "/?X-Tenant-ID=evilq", strings.NewReader(`{"X-Tenant-ID":"evilb"}`))
+	if err := BindRequest(r, &tr); err != nil || tr.Tenant != "" {
+		t.Fatalf("header field bound from another source: %+v %v", tr, err)
+	}
+
+	params := map[string]string{"id": "5", "org_id": "6"}
+	r = WithPathValues(httptest.NewRequest(http.MethodGet, "/", nil), PathValuerFunc(func(n string) string { return params[n] }))
+	r.AddCookie(&http.Cookie{Name: "session", Value: "xyz"})
+	var p updateUser
+	if err := BindPath(r, &p); err != nil || p.ID != 5 || p.OrgID != 6 {
+		t.Fatalf("BindPath: %+v %v", p, err)
+	}
+	if err := BindCookies(r, &p); err != nil || p.Session != "xyz" {
+		t.Fatalf("BindCookies: %+v %v", p, err)
+	}
+}
+
+func TestBindRequestLimits(t *testing.T) {
+	post := func(body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		return r
+	}
+	var re *RequestError
+	err := BindRequestWith(post(`{"name":"`+strings.Repeat("x", 200)+`"}`), &bodyUser{}, WithMaxBodyBytes(64))
+	if !errors.As(err, &re) || re.Status != http.StatusRequestEntityTooLarge || !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("body cap: %v", err)
+	}
+	err = BindRequestWith(post(`{"name":"`+strings.Repeat("x", 20)+`"}`), &bodyUser{}, WithMaxStringLen(10))
+	if !errors.As(err, &re) || re.Status != http.StatusBadRequest || !errors.Is(err, ErrOverflow) || !strings.Contains(err.Error(), "name") {
+		t.Fatalf("string cap: %v", err)
+	}
+	err = BindJSONWith(post(`{"tags":["a","b","c"]}`), &bodyUser{}, WithBindDTOOptions(WithDTOMaxSliceLen(2)))
+	if !errors.Is(err, ErrOverflow) || !strings.Contains(err.Error(), "tags") {
+		t.Fatalf("array cap: %v", err)
+	}
+	err = BindJSONWith(post(`{"a":{"b":{"c":{}}}}`), &map[string]any{}, WithBindDTOOptions(WithDTOMaxDepth(2)))
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("depth cap: %v", err)
+	}
+	if err := BindJSONWith(post(`{"a":{"b":{}}}`), &map[string]any{}, WithBindDTOOptions(WithDTOMaxDepth(2))); err != nil {
+		t.Fatalf("depth at the limit: %v", err)
+	}
+	err = BindJSONWith(post(`{"id":1} {"id":2}`), &bodyUser{})
+	if !errors.As(err, &re) || re.Status != http.StatusBadRequest || !errors.Is(err, ErrMalformedBody) {
+		t.Fatalf("trailing data: %v", err)
+	}
+	if err := BindJSON(post(`{"name":"`+strings.Repeat("x", 2<<20)+`"}`), &bodyUser{}); err != nil {
+		t.Fatalf("BindJSON must stay uncapped: %v", err)
+	}
+	err = BindRequestWith(post(`{"id":1,"nmae":"x"}`), &bodyUser{}, WithDisallowUnknownFields())
+	if !errors.As(err, &re) || re.Status != http.StatusBadRequest || !errors.Is(err, ErrUnknownKey) || !strings.Contains(err.Error(), `did you mean "name"?`) {
+		t.Fatalf("unknown fields: %v", err)
+	}
+	r := post(`{"id":1}`)
+	r.URL.RawQuery = "extra=1"
+	var u bodyUser
+	if err := BindRequestWith(r, &u, WithDisallowUnknownFields()); err != nil || u.ID != 1 {
+		t.Fatalf("only body keys are checked: %+v %v", u, err)
+	}
+	if err := BindJSON(post(``), &u); !errors.Is(err, ErrMalformedBody) {
+		t.Fatalf("empty JSON body: %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	type createReq struct {
+		OrgID int    `json:"org_id,from=path"`
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email" validate:"email"`
+	}
+	type account struct {
+		Token string `json:"token" sensitive:"true"`
+		Plan  string `json:"plan"`
+	}
+	type createResp struct {
+		ID       int       `json:"id"`
+		Password string    `json:"password,writeonly"`
+		Accounts []account `json:"accounts"`
+	}
+	h := Handler(func(ctx context.Context, req createReq) (createResp, error) {
+		if req.Name == "boom" {
+			return createResp{}, errors.New("database password leaked in message")
+		}
+		return createResp{ID: req.OrgID, Password: "pw", Accounts: []account{{Token: "tok-123", Plan: "pro"}}}, nil
+	})
+	mux := http.NewServeMux()
+	mux.Handle("POST /orgs/{org_id}/users", h)
+	do := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orgs/7/users", strings.NewReader(body)))
+		return w
+	}
+
+	w := do(`{"name":"Ann","email":"ann@example.com"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":7`) || !strings.Contains(w.Body.String(), `"plan":"pro"`) {
+		t.Fatalf("success: %d %s", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "tok-123") || strings.Contains(w.Body.String(), "pw") {
+		t.Fatalf("response leaked sensitive data: %s", w.Body)
+	}
+
+	w = do(`{"email":"nope"}`)
+	if w.Code != http.StatusUnprocessableEntity || w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Fatalf("validation: %d %s", w.Code, w.Body)
+	}
+	for _, want := range []string{`"status":422`, `"code":"empty"`, `"path":"name"`, `"path":"email"`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Fatalf("missing %s in %s", want, w.Body)
+		}
+	}
+
+	if w = do(`{"name":`); w.Code != http.StatusBadRequest {
+		t.Fatalf("malformed: %d %s", w.Code, w.Body)
+	}
+	if w = do(`{"name":"boom"}`); w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "password") {
+		t.Fatalf("server error: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	type login struct {
+		User string `json:"user"`
+		PIN  int    `json:"pin" sensitive:"true"`
+		Age  int    `json:"age"`
+	}
+	_, err := MapAll[login](map[string]any{"user": "ann", "pin": "12x4", "age": "old"})
+	p := ProblemDetails(err)
+	if p.Status != http.StatusBadRequest || p.Type != "urn:convert:problem:invalid" || len(p.Errors) != 2 {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+	byPath := map[string]ProblemError{}
+	for _, e := range p.Errors {
+		byPath[e.Path] = e
+	}
+	if byPath["pin"].Value != "[REDACTED]" || byPath["pin"].To != "int" || byPath["age"].Value != "old" || byPath["age"].Code != "invalid" {
+		t.Fatalf("unexpected errors: %+v", p.Errors)
+	}
+	if s := err.Error(); strings.Contains(s, "12x4") || !strings.Contains(s, "old") {
+		t.Fatalf("DebugError must redact only sensitive values: %s", s)
+	}
+
+	RegisterProblemType(CodeInvalid, http.StatusUnprocessableEntity, "https://example.com/problems/invalid")
+	defer problemTypes.Delete(CodeInvalid)
+	if p := ProblemDetails(err); p.Status != http.StatusUnprocessableEntity || p.Type != "https://example.com/problems/invalid" {
+		t.Fatalf("registered type: %+v", p)
+	}
+	if p := ProblemDetails(&RequestError{Status: http.StatusUnsupportedMediaType, Err: ErrUnsupportedMediaType}); p.Status != 415 || p.Type != "urn:convert:problem:unsupported_media_type" {
+		t.Fatalf("request error: %+v", p)
+	}
+	if p := ProblemDetails(errors.New("db down")); p.Status != 500 || p.Detail != "" || p.Type != "about:blank" {
+		t.Fatalf("internal error: %+v", p)
+	}
+}
@@ -0,0 +1,216 @@
+package convert
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ImportReject is a record that could not be converted.
+type ImportReject struct {
+	Line   int      // 1-based source line the record starts on
+	Record []string // CSV fields, when the record could be parsed
+	Raw    string   // raw JSON line, or the raw text of a malformed CSV record
+	Err    error
+}
+
+func (r ImportReject) Error() string { return "line " + strconv.Itoa(r.Line) + ": " + r.Err.Error() }
+func (r ImportReject) Unwrap() error { return r.Err }
+
+// Details returns the field errors of the reject.
+func (r ImportReject) Details() []*ErrorDetail { return errorDetails(r.Err, nil) }
+
+// Paths returns the field paths that failed, e.g. ["age", "address.zip"].
+func (r ImportReject) Paths() []string {
+	var out []string
+	for _, d := range r.Details() {
+		out = append(out, d.Path)
+	}
+	return out
+}
+
+func errorDetails(err error, out []*ErrorDetail) []*ErrorDetail {
+	switch e := err.(type) {
+	case nil:
+	case *ErrorDetail:
+		out = append(out, e)
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			out = errorDetails(err, out)
+		}
+	case interface{ Unwrap() error }:
+		out = errorDetails(e.Unwrap(), out)
+	}
+	return out
+}
+
+// ImportReport holds the converted records and the rejected ones in source order.
+type ImportReport[T any] struct {
+	Values  []T
+	Rejects []ImportReject
+}
+
+// ImportOptions configures ImportCSV and ImportJSONL.
+type ImportOptions struct {
+	CSV        []CSVOption
+	DTOOptions []DTOOption
+	// Quarantine receives rejected records: CSV rows fitted to the header with extra _error
+	// and _raw columns (_raw holds the text of malformed rows, so fields past the header
+	// are only kept there), or JSON lines with an _error key (non-object lines are kept
+	// under _raw).
+	Quarantine io.Writer
+}
+
+type ImportOption func(*ImportOptions)
+
+func WithImportCSV(opts ...CSVOption) ImportOption {
+	return func(o *ImportOptions) { o.CSV = append(o.CSV, opts...) }
+}
+func WithImportDTOOptions(opts ...DTOOption) ImportOption {
+	return func(o *ImportOptions) { o.DTOOptions = append(o.DTOOptions, opts...) }
+}
+func WithQuarantine(w io.Writer) ImportOption {
+	return func(o *ImportOptions) { o.Quarantine = w }
+}
+
+func importOptionsFrom(opts []ImportOption) ImportOptions {
+	var o ImportOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// ImportCSV converts every record it can and reports the others with their line number
+// and fields, or raw text when malformed, instead of stopping at the first bad row like
+// ReadCSV. Malformed rows are rejected too; only read and quarantine write errors are
+// returned.
+func ImportCSV[T any](r io.Reader, opts ...ImportOption) (ImportReport[T], error) {
+	var rep ImportReport[T]
+	imp := importOptionsFrom(opts)
+	o := csvOptionsFrom(append(imp.CSV, WithCSVDTOOptions(imp.DTOOptions...)))
+	s, err := openCSV[T](r, o, true)
+	if s == nil {
+		return rep, err
+	}
+	var q *csv.Writer
+	for {
+		row, line, err := s.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var pe *csv.ParseError
+		if err != nil && !errors.As(err, &pe) {
+			return rep, err
+		}
+		raw := s.raw()
+		rej := ImportReject{Line: line, Err: err}
+		if err == nil || errors.Is(err, csv.ErrFieldCount) {
+			rej.Record = append([]string(nil), row...)
+		}
+		if err == nil {
+			m, err := s.dec.record(row)
+			if err == nil {
+				var v T
+				if v, err = importValue[T](m, s.all); err == nil {
+					rep.Values = append(rep.Values, v)
+					continue
+				}
+			}
+			rej.Err = err
+		} else {
+			rej.Line, rej.Raw = pe.StartLine, raw
+		}
+		rep.Rejects = append(rep.Rejects, rej)
+		if imp.Quarantine == nil {
+			continue
+		}
+		if q == nil {
+			q = csv.NewWriter(imp.Quarantine)
+			q.Comma = o.Comma
+			if s.header != nil {
+				if err := q.Write(append(append([]string(nil), s.header...), "_error", "_raw")); err != nil {
+					return rep, err
+				}
+			}
+		}
+		cells := rej.Record
+		if s.header != nil {
+			cells = make([]string, len(s.header))
+			copy(cells, rej.Record)
+		}
+		if err := q.Write(append(cells, rej.Err.Error(), rej.Raw)); err != nil {
+			return rep, err
+		}
+	}
+	if q != nil {
+		q.Flush()
+		return rep, q.Error()
+	}
+	return rep, nil
+}
+
+// ImportJSONL converts every JSON line it can and reports the others with their line
+// number and raw text, instead of stopping at the first error like DTOStreamJSONL. Blank
+// lines are skipped; only read and quarantine write errors are returned.
+func ImportJSONL[T any](r io.Reader, opts ...ImportOption) (ImportReport[T], error) {
+	var rep ImportReport[T]
+	o := importOptionsFrom(opts)
+	var q *json.Encoder
+	if o.Quarantine != nil {
+		q = json.NewEncoder(o.Quarantine)
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		raw := strings.TrimSpace(sc.Text())
+		if raw == "" {
+			continue
+		}
+		var m any
+		err := json.Unmarshal([]byte(raw), &m)
+		if err == nil {
+			var v T
+			if v, err = importValue[T](m, o.DTOOptions); err == nil {
+				rep.Values = append(rep.Values, v)
+				continue
+			}
+		}
+		rej := ImportReject{Line: line, Raw: raw, Err: err}
+		rep.Rejects = append(rep.Rejects, rej)
+		if q == nil {
+			continue
+		}
+		obj, ok := m.(map[string]any)
+		if !ok {
+			obj = map[string]any{"_raw": raw}
+		}
+		obj["_error"] = err.Error()
+		if err := q.Encode(obj); err != nil {
+			return rep, err
+		}
+	}
+	return rep, sc.Err()
+}
+
+// importValue converts src once and only walks it again to collect every field error
+// when that fails, as MapAll would.
+func importValue[T any](src any, opts []DTOOption) (T, error) {
+	var out T
+	err := DTO(&out, src, opts...)
+	if err == nil {
+		return out, nil
+	}
+	var z T
+	if errs := collectDTOErrors(reflect.ValueOf(&z).Elem(), src, "", dtoOptionsFrom(opts)); len(errs) > 0 {
+		return out, MultiError{Errors: errs}
+	}
+	return out, err
+}
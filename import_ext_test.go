@@ -0,0 +1,66 @@
+package convert
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type importRec struct {
+	ID   int    `json:"id" csv:"id"`
+	Age  int    `json:"age" csv:"age"`
+	Name string `json:"name" csv:"name" validate:"required"`
+}
+
+func TestImportCSV(t *testing.T) {
+	in := "id;age;name\n1;30;ada\nx;y;bob\n3;40;\"multi\nline\"\n4;z;\"bad\"quote\n5;50;eve\n6;60\n"
+	var q bytes.Buffer
+	rep, err := ImportCSV[importRec](strings.NewReader(in), WithImportCSV(WithCSVComma(';')), WithQuarantine(&q))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Values) != 3 || rep.Values[1].Name != "multi\nline" || rep.Values[2].ID != 5 {
+		t.Fatalf("values: %+v", rep.Values)
+	}
+	if len(rep.Rejects) != 3 {
+		t.Fatalf("rejects: %+v", rep.Rejects)
+	}
+	r := rep.Rejects[0]
+	if r.Line != 3 || !reflect.DeepEqual(r.Record, []string{"x", "y", "bob"}) || !reflect.DeepEqual(r.Paths(), []string{"id", "age"}) {
+		t.Fatalf("reject: %+v %v", r, r.Paths())
+	}
+	if r := rep.Rejects[1]; r.Line != 6 || r.Raw != `4;z;"bad"quote` {
+		t.Fatalf("parse reject: %+v", r)
+	}
+	if r := rep.Rejects[2]; r.Line != 8 || r.Raw != "6;60" || !reflect.DeepEqual(r.Record, []string{"6", "60"}) {
+		t.Fatalf("field count reject: %+v", r)
+	}
+	lines := strings.Split(q.String(), "\n")
+	if lines[0] != "id;age;name;_error;_raw" || !strings.HasPrefix(lines[1], "x;y;bob;") || !strings.HasSuffix(lines[1], ";") ||
+		!strings.HasPrefix(lines[2], ";;;") || !strings.HasSuffix(lines[2], `;"4;z;""bad""quote"`) || !strings.HasPrefix(lines[3], "6;60;;") {
+		t.Fatalf("quarantine: %q", q.String())
+	}
+}
+
+func TestImportJSONL(t *testing.T) {
+	in := "{\"id\":1,\"name\":\"a\"}\n\n{\"id\":\"x\",\"name\":\"b\"}\nnope\n{\"id\":2}\n"
+	var q bytes.Buffer
+	rep, err := ImportJSONL[importRec](strings.NewReader(in), WithQuarantine(&q))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Values) != 1 || len(rep.Rejects) != 3 {
+		t.Fatalf("report: %+v", rep)
+	}
+	if r := rep.Rejects[0]; r.Line != 3 || r.Raw != "{\"id\":\"x\",\"name\":\"b\"}" || !reflect.DeepEqual(r.Paths(), []string{"id"}) {
+		t.Fatalf("reject: %+v", r)
+	}
+	if r := rep.Rejects[2]; r.Line != 5 || !reflect.DeepEqual(r.Paths(), []string{"name"}) {
+		t.Fatalf("validation reject: %+v %v", r, r.Paths())
+	}
+	out := strings.Split(strings.TrimSpace(q.String()), "\n")
+	if len(out) != 3 || !strings.Contains(out[0], "\"_error\"") || !strings.Contains(out[1], "\"_raw\":\"nope\"") {
+		t.Fatalf("quarantine: %q", q.String())
+	}
+}
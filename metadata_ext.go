@@ -0,0 +1,79 @@
+package convert
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+)
+
+// FromMetadata decodes gRPC-style metadata into T using header tags. Keys match case-insensitively
+// and values of keys ending in -bin are base64-decoded first, padded or not. A metadata.MD can
+// be passed directly since it is a map[string][]string.
+func FromMetadata[T any](md map[string][]string, opts ...DTOOption) (T, error) {
+	var zero T
+	m := headerValuesMap(md)
+	for k, vs := range md {
+		if !isBinaryMetadataKey(k) {
+			continue
+		}
+		out := make([][]byte, len(vs))
+		for i, v := range vs {
+			b, err := decodeMetadataBinary(v)
+			if err != nil {
+				return zero, PathError(k, KindString, KindBytes, v, err)
+			}
+			out[i] = b
+		}
+		if len(out) == 1 {
+			m[k] = out[0]
+		} else {
+			m[k] = out
+		}
+	}
+	return DTOTo[T](m, append([]DTOOption{WithDTOTags("header", "json", "convert")}, opts...)...)
+}
+
+// ToMetadata encodes src as gRPC-style metadata: lower-case keys, one value per slice element,
+// and base64 (unpadded, as gRPC sends it) for keys ending in -bin.
+func ToMetadata(src any, opts ...DTOOption) (map[string][]string, error) {
+	rv := reflect.ValueOf(src)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, ErrNil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, PathError("", KindOf(src), KindStruct, src, ErrUnsupported)
+	}
+	all := append([]DTOOption{WithDTOTags("header", "json", "convert")}, opts...)
+	md := map[string][]string{}
+	for _, f := range dtoMetaFor(rv.Type(), dtoOptionsFrom(all)).fields {
+		fv := fieldByIndex(rv, f.index)
+		if f.writeonly || !fv.IsValid() || !fv.CanInterface() || hasTagOption(f.structField, "omitempty") && fv.IsZero() {
+			continue
+		}
+		key := strings.ToLower(f.primary)
+		values, err := fieldStrings(fv, key)
+		if err != nil {
+			return nil, err
+		}
+		if isBinaryMetadataKey(key) {
+			for i, v := range values {
+				values[i] = base64.RawStdEncoding.EncodeToString([]byte(v))
+			}
+		}
+		if len(values) > 0 {
+			md[key] = append(md[key], values...)
+		}
+	}
+	return md, nil
+}
+
+func isBinaryMetadataKey(k string) bool { return strings.HasSuffix(strings.ToLower(k), "-bin") }
+func decodeMetadataBinary(v string) ([]byte, error) {
+	if len(v)%4 == 0 {
+		return base64.StdEncoding.DecodeString(v)
+	}
+	return base64.RawStdEncoding.DecodeString(v)
+}
@@ -0,0 +1,65 @@
+package convert
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type rpcMeta struct {
+	RequestID string   `header:"x-request-id"`
+	Tenants   []string `header:"x-tenant"`
+	Token     []byte   `header:"auth-token-bin"`
+	Trace     string   `header:"trace-bin,omitempty"`
+}
+
+func TestMetadata(t *testing.T) {
+	md, err := ToMetadata(rpcMeta{RequestID: "r-1", Tenants: []string{"a", "b"}, Token: []byte{0xff, 0x00, 0x01}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"x-request-id": {"r-1"}, "x-tenant": {"a", "b"}, "auth-token-bin": {"/wAB"}}
+	if !reflect.DeepEqual(md, want) {
+		t.Fatalf("ToMetadata = %v", md)
+	}
+	md["trace-bin"] = []string{"aGk"}
+	got, err := FromMetadata[rpcMeta](md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RequestID != "r-1" || !reflect.DeepEqual(got.Tenants, []string{"a", "b"}) || !reflect.DeepEqual(got.Token, []byte{0xff, 0x00, 0x01}) || got.Trace != "hi" {
+		t.Fatalf("FromMetadata = %+v", got)
+	}
+	_, err = FromMetadata[rpcMeta](map[string][]string{"auth-token-bin": {"!!"}})
+	var ce *ErrorDetail
+	if !errors.As(err, &ce) || ce.Path != "auth-token-bin" {
+		t.Fatalf("bad -bin value: %v", err)
+	}
+}
+
+func TestBindResponseTrailers(t *testing.T) {
+	type resp struct {
+		Name    string `json:"name"`
+		Status  string `trailer:"Grpc-Status"`
+		Message string `trailer:"Grpc-Message"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"name":"Ann"}`)
+		w.Header().Set("Grpc-Status", "0")
+		w.Header().Set("Grpc-Message", "ok")
+	}))
+	defer srv.Close()
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := BindResponse[resp, any](res)
+	if err != nil || got.Name != "Ann" || got.Status != "0" || got.Message != "ok" {
+		t.Fatalf("got %+v %v", got, err)
+	}
+}
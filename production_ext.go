@@ -19,7 +19,7 @@ type TagPolicy struct {
 }
 
 func DefaultTagPolicy() TagPolicy {
-	return TagPolicy{Tags: []string{"convert", "json", "env", "query", "form", "header", "csv"}, UseFieldName: true, CaseInsensitive: true}
+	return TagPolicy{Tags: []string{"convert", "json", "env", "query", "form", "header", "csv", "path", "cookie"}, UseFieldName: true, CaseInsensitive: true}
 }
 
 type PopulateOption func(*populatePolicy)
@@ -900,6 +900,12 @@ func validateReflectField(v reflect.Value, f reflect.StructField, path string) e
 		if rule == "" || rule == "required" {
 			continue
 		}
+		if name, param, ok := strings.Cut(rule, "="); ok {
+			if err := validateFileRule(v, name, param, path); err != nil {
+				return err
+			}
+			continue
+		}
 		var err error
 		switch rule {
 		case "email":
@@ -0,0 +1,278 @@
+package convert
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Query serialization styles, selected per field with the style= and explode=false tag
+// options as in OpenAPI, e.g. `query:"filter,style=deepObject"` or `query:"ids,explode=false"`.
+const (
+	QueryStyleForm           = "form"           // tags=a&tags=b, or tags=a,b with explode=false
+	QueryStyleSpaceDelimited = "spaceDelimited" // tags=a%20b
+	QueryStylePipeDelimited  = "pipeDelimited"  // tags=a|b
+	QueryStyleDeepObject     = "deepObject"     // filter[status]=x
+	QueryStyleDot            = "dot"            // filter.status=x
+)
+
+// queryStyle returns a field's style and explode setting. Exploded objects default to dot
+// notation and everything else to form style.
+func queryStyle(f reflect.StructField) (style string, explode bool) {
+	explode = true
+	for _, tag := range dtoOptionTags {
+		for _, p := range strings.Split(f.Tag.Get(tag), ",")[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			switch k {
+			case "style":
+				style = v
+			case "explode":
+				explode = v != "false"
+			}
+		}
+	}
+	switch {
+	case style != "":
+	case explode && isQueryObject(f.Type):
+		style = QueryStyleDot
+	default:
+		style = QueryStyleForm
+	}
+	return style, explode
+}
+
+// isQueryObject reports whether t is written key by key. Types that encode themselves, such
+// as time.Time, TextMarshaler, json.Marshaler and driver.Valuer types, are single values.
+func isQueryObject(t reflect.Type) bool {
+	t = indirectType(t)
+	if t.Kind() != reflect.Map && t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) || selfEncoding(t) {
+		return false
+	}
+	return !t.Implements(valuerType) && !reflect.PointerTo(t).Implements(valuerType)
+}
+func queryDelimiter(style string, explode bool) string {
+	switch style {
+	case QueryStyleSpaceDelimited:
+		return " "
+	case QueryStylePipeDelimited:
+		return "|"
+	case QueryStyleForm:
+		if !explode {
+			return ","
+		}
+	}
+	return ""
+}
+
+// queryToMap is valuesToMap with deepObject keys nested: filter[status]=x becomes
+// {"filter": {"status": "x"}} and ids[]=1&ids[]=2 becomes {"ids": ["1", "2"]}.
+func queryToMap(v url.Values) map[string]any {
+	m := map[string]any{}
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts, ok := bracketPath(k)
+		if !ok {
+			m[k] = queryValue(v[k])
+			continue
+		}
+		cur := m
+		for _, p := range parts[:len(parts)-2] {
+			next, ok := cur[p].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				cur[p] = next
+			}
+			cur = next
+		}
+		parent, last := parts[len(parts)-2], parts[len(parts)-1]
+		if last == "" {
+			items, _ := cur[parent].([]string)
+			cur[parent] = append(items, v[k]...)
+			continue
+		}
+		next, ok := cur[parent].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			cur[parent] = next
+		}
+		next[last] = queryValue(v[k])
+	}
+	return m
+}
+
+// bracketPath splits a[b][c] into [a b c]; ok is false for keys without well-formed brackets.
+func bracketPath(k string) ([]string, bool) {
+	i := strings.IndexByte(k, '[')
+	if i <= 0 || !strings.HasSuffix(k, "]") {
+		return nil, false
+	}
+	parts := []string{k[:i]}
+	for rest := k[i:]; rest != ""; {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return nil, false
+		}
+		parts = append(parts, rest[1:end])
+		rest = rest[end+1:]
+		if end == 1 && rest != "" {
+			return nil, false
+		}
+	}
+	return parts, true
+}
+func queryValue(vs []string) any {
+	if len(vs) == 1 {
+		return vs[0]
+	}
+	return vs
+}
+
+// queryValuesMap decodes v for t, applying each field's query style on top of queryToMap.
+func queryValuesMap(v url.Values, t reflect.Type, opt DTOOptions) map[string]any {
+	m := queryToMap(v)
+	t = indirectType(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return m
+	}
+	for _, f := range dtoMetaFor(t, opt).fields {
+		style, explode := queryStyle(f.structField)
+		for _, n := range f.names {
+			sep := queryDelimiter(style, explode)
+			if sep == "" && style != QueryStyleDeepObject && isQueryObject(f.structField.Type) {
+				nestDottedKeys(m, n)
+				continue
+			}
+			raw, ok := m[n]
+			if !ok || sep == "" {
+				continue
+			}
+			var items []string
+			for _, s := range stringsOf(raw) {
+				items = append(items, strings.Split(s, sep)...)
+			}
+			if isQueryObject(f.structField.Type) {
+				obj := map[string]any{}
+				for i := 0; i+1 < len(items); i += 2 {
+					obj[items[i]] = items[i+1]
+				}
+				m[n] = obj
+				continue
+			}
+			m[n] = items
+		}
+	}
+	return m
+}
+func nestDottedKeys(m map[string]any, name string) {
+	prefix := name + "."
+	sub := map[string]any{}
+	for k, v := range m {
+		if rest, ok := strings.CutPrefix(k, prefix); ok && rest != "" {
+			sub[rest] = v
+			delete(m, k)
+		}
+	}
+	if len(sub) == 0 {
+		return
+	}
+	nested := UnflattenMap(sub)
+	if existing, ok := m[name].(map[string]any); ok {
+		nested = mergeConfigMaps(existing, nested)
+	}
+	m[name] = nested
+}
+func stringsOf(v any) []string {
+	switch x := v.(type) {
+	case string:
+		return []string{x}
+	case []string:
+		return x
+	}
+	return nil
+}
+
+// encodeQueryField adds one field to q in the given style.
+func encodeQueryField(q url.Values, name string, v reflect.Value, style string, explode bool, opt DTOOptions) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	sep := queryDelimiter(style, explode)
+	if isQueryObject(v.Type()) {
+		var pairs []string
+		err := walkQueryObject(v, nil, opt, 0, func(path []string, s string) {
+			switch {
+			case style == QueryStyleDeepObject:
+				q.Add(name+"["+strings.Join(path, "][")+"]", s)
+			case sep != "":
+				pairs = append(pairs, strings.Join(path, "."), s)
+			default:
+				q.Add(name+"."+strings.Join(path, "."), s)
+			}
+		})
+		if len(pairs) > 0 {
+			q.Set(name, strings.Join(pairs, sep))
+		}
+		return err
+	}
+	values, err := fieldStrings(v, name)
+	if err != nil {
+		return err
+	}
+	if sep != "" && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) {
+		q.Set(name, strings.Join(values, sep))
+		return nil
+	}
+	for _, s := range values {
+		q.Add(name, s)
+	}
+	return nil
+}
+
+// walkQueryObject calls emit for every leaf of a struct or string-keyed map in key order.
+func walkQueryObject(v reflect.Value, path []string, opt DTOOptions, depth int, emit func([]string, string)) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if opt.MaxDepth > 0 && depth > opt.MaxDepth {
+		return PathError(strings.Join(path, "."), KindOfReflect(v), KindString, nil, ErrOverflow)
+	}
+	switch {
+	case v.Kind() == reflect.Struct && isQueryObject(v.Type()):
+		for _, f := range dtoMetaFor(v.Type(), opt).fields {
+			fv := fieldByIndex(v, f.index)
+			if f.writeonly || !fv.IsValid() || !fv.CanInterface() {
+				continue
+			}
+			if err := walkQueryObject(fv, append(path[:len(path):len(path)], f.primary), opt, depth+1, emit); err != nil {
+				return err
+			}
+		}
+		return nil
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			if err := walkQueryObject(v.MapIndex(k), append(path[:len(path):len(path)], k.String()), opt, depth+1, emit); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	values, err := fieldStrings(v, strings.Join(path, "."))
+	for _, s := range values {
+		emit(path, s)
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+package convert
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type queryFilter struct {
+	Status string `query:"status"`
+	Owner  struct {
+		ID int `query:"id"`
+	} `query:"owner"`
+}
+
+type queryListing struct {
+	Filter queryFilter       `query:"filter,style=deepObject"`
+	Sort   map[string]string `query:"sort,style=deepObject"`
+	Page   struct {
+		Size int `query:"size"`
+	} `query:"page"`
+	IDs   []int          `query:"ids,explode=false"`
+	Tags  []string       `query:"tags"`
+	Words []string       `query:"words,style=spaceDelimited"`
+	Roles []string       `query:"roles,style=pipeDelimited"`
+	Color map[string]int `query:"color,explode=false"`
+}
+
+func TestQueryStyles(t *testing.T) {
+	q, _ := url.ParseQuery("filter[status]=open&filter[owner][id]=7&sort[created]=desc&page.size=20&ids=1,2,3&tags=a&tags=b&words=x%20y&roles=admin|dev&color=R,100,G,200")
+	got, err := FromQuery[queryListing](q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Filter.Status != "open" || got.Filter.Owner.ID != 7 || got.Sort["created"] != "desc" || got.Page.Size != 20 {
+		t.Fatalf("objects: %+v", got)
+	}
+	if !reflect.DeepEqual(got.IDs, []int{1, 2, 3}) || !reflect.DeepEqual(got.Tags, []string{"a", "b"}) ||
+		!reflect.DeepEqual(got.Words, []string{"x", "y"}) || !reflect.DeepEqual(got.Roles, []string{"admin", "dev"}) {
+		t.Fatalf("arrays: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Color, map[string]int{"R": 100, "G": 200}) {
+		t.Fatalf("form object: %+v", got.Color)
+	}
+
+	out, err := ToQuery(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string][]string{
+		"filter[status]": {"open"}, "filter[owner][id]": {"7"}, "sort[created]": {"desc"}, "page.size": {"20"},
+		"ids": {"1,2,3"}, "tags": {"a", "b"}, "words": {"x y"}, "roles": {"admin|dev"}, "color": {"G,200,R,100"},
+	} {
+		if !reflect.DeepEqual(out[k], want) {
+			t.Fatalf("ToQuery %s = %q, want %q (%v)", k, out[k], want, out)
+		}
+	}
+	back, err := FromQuery[queryListing](out)
+	if err != nil || !reflect.DeepEqual(back, got) {
+		t.Fatalf("round trip: %+v %v", back, err)
+	}
+
+	var bound queryListing
+	r := httptest.NewRequest("GET", "/items?filter[status]=closed&ids[]=4&ids[]=5", nil)
+	if err := BindRequestQuery(r, &bound); err != nil || bound.Filter.Status != "closed" || !reflect.DeepEqual(bound.IDs, []int{4, 5}) {
+		t.Fatalf("BindRequestQuery: %+v %v", bound, err)
+	}
+	bound = queryListing{}
+	if err := BindRequest(httptest.NewRequest("GET", "/items?filter[owner][id]=9&roles=a|b", nil), &bound); err != nil || bound.Filter.Owner.ID != 9 || len(bound.Roles) != 2 {
+		t.Fatalf("BindRequest: %+v %v", bound, err)
+	}
+}
+
+func TestQuerySelfEncodingValues(t *testing.T) {
+	type search struct {
+		Addr  netip.Addr `query:"addr"`
+		Since time.Time  `query:"since"`
+	}
+	in := search{Addr: netip.MustParseAddr("10.0.0.1"), Since: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	q, err := ToQuery(in)
+	if err != nil || q.Get("addr") != "10.0.0.1" || q.Get("since") != "2024-05-01T00:00:00Z" || len(q) != 2 {
+		t.Fatalf("ToQuery: %v %v", q, err)
+	}
+	type window struct {
+		Since time.Time `query:"since"`
+	}
+	back, err := FromQuery[window](q)
+	if err != nil || !back.Since.Equal(in.Since) {
+		t.Fatalf("FromQuery: %+v %v", back, err)
+	}
+}
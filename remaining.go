@@ -40,6 +40,8 @@ type ErrorDetail struct {
 	To    Kind
 	Value any
 	Cause error
+	// Sensitive marks errors from sensitive fields; renderers redact Value.
+	Sensitive bool
 }
 
 func (e *ErrorDetail) Error() string {
@@ -66,27 +68,31 @@ func (e *ErrorDetail) Unwrap() error {
 	}
 	return e.Cause
 }
+
+// codeNames holds the stable snake_case name and the message text of each Code.
+var codeNames = map[Code][2]string{
+	CodeUnsupported:      {"unsupported", "unsupported conversion"},
+	CodeInvalid:          {"invalid", "invalid value"},
+	CodeOverflow:         {"overflow", "overflow"},
+	CodeEmpty:            {"empty", "empty value"},
+	CodeNil:              {"nil", "nil value"},
+	CodePrecisionLoss:    {"precision_loss", "precision loss"},
+	CodeUnsafe:           {"unsafe", "unsafe conversion"},
+	CodeValidationFailed: {"validation_failed", "validation failed"},
+}
+
+// String returns the stable snake_case name of c, e.g. "precision_loss".
+func (c Code) String() string {
+	if n, ok := codeNames[c]; ok {
+		return n[0]
+	}
+	return "unknown"
+}
 func codeString(c Code) string {
-	switch c {
-	case CodeUnsupported:
-		return "unsupported conversion"
-	case CodeInvalid:
-		return "invalid value"
-	case CodeOverflow:
-		return "overflow"
-	case CodeEmpty:
-		return "empty value"
-	case CodeNil:
-		return "nil value"
-	case CodePrecisionLoss:
-		return "precision loss"
-	case CodeUnsafe:
-		return "unsafe conversion"
-	case CodeValidationFailed:
-		return "validation failed"
-	default:
-		return "conversion failed"
+	if n, ok := codeNames[c]; ok {
+		return n[1]
 	}
+	return "conversion failed"
 }
 func PathError(path string, from, to Kind, value any, cause error) error {
 	c := CodeInvalid
@@ -107,6 +113,15 @@ func PathError(path string, from, to Kind, value any, cause error) error {
 	return &ErrorDetail{Code: c, Path: path, From: from, To: to, Value: value, Cause: cause}
 }
 
+// markSensitive flags the ErrorDetail in err when it comes from a sensitive field.
+func markSensitive(err error, sensitive bool) error {
+	var d *ErrorDetail
+	if sensitive && errors.As(err, &d) {
+		d.Sensitive = true
+	}
+	return err
+}
+
 // Precision and Unix/time policy options.
 func NoPrecisionLoss() OptionFunc {
 	return func(p *Policy) { p.FloatToIntOnlyExact = true; p.OverflowCheck = true }
@@ -0,0 +1,1156 @@
+package convert
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sqlScanMode says how a column reaches its struct field.
+type sqlScanMode uint8
+
+const (
+	sqlScanDiscard sqlScanMode = iota // no matching field, or a readonly one
+	sqlScanDirect                     // Scanner or pointer field: scan into the field itself
+	sqlScanNull                       // string or number: scan into a **T temporary, NULL leaves zero
+	sqlScanConvert                    // anything else: scan into any, normalize and run dtoSet unless NULL
+)
+
+type sqlColumn struct {
+	mode  sqlScanMode
+	index []int
+	path  string
+	field *dtoFieldMeta
+	typ   reflect.Type
+}
+
+// sqlPlan maps result columns to struct fields once per (T, columns, tags).
+type sqlPlan struct {
+	cols     []sqlColumn
+	defaults []sqlColumn // fields with a default tag and no column
+	meta     *dtoStructMeta
+	missing  error // a required field has no column; reported on the first row
+}
+
+type sqlPlanKey struct {
+	typ    reflect.Type
+	cols   string
+	tags   string
+	ci     bool
+	gen    bool
+	strict bool
+	depth  int
+}
+
+var (
+	sqlPlans    sync.Map // map[sqlPlanKey]*sqlPlan
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// sqlPlanFor returns the column plan for t, or ok=false if t is not a plain struct and rows
+// must go through the map-based DTO path.
+func sqlPlanFor(t reflect.Type, cols []string, opt DTOOptions) (*sqlPlan, bool, error) {
+	if t.Kind() != reflect.Struct || !sqlDescend(t) {
+		return nil, false, nil
+	}
+	generic := opt.DecodeHook != nil || opt.ResolveSecrets
+	key := sqlPlanKey{typ: t, cols: strings.Join(cols, "\x00"), tags: strings.Join(opt.TagPolicy.Tags, "\x00"), ci: opt.TagPolicy.CaseInsensitive, gen: generic, strict: opt.ErrorUnused, depth: opt.MaxDepth}
+	if opt.UseCache {
+		if p, ok := sqlPlans.Load(key); ok {
+			return p.(*sqlPlan), true, nil
+		}
+	}
+	p := &sqlPlan{cols: make([]sqlColumn, len(cols)), meta: dtoMetaFor(t, opt)}
+	for i, c := range cols {
+		col, ok := sqlMatchColumn(t, c, nil, "", opt, 0)
+		if !ok {
+			if opt.ErrorUnused {
+				return nil, true, PathError(c, KindInvalid, KindInvalid, nil, ErrUnusedField)
+			}
+			continue
+		}
+		switch {
+		case col.field.readonly:
+			col.mode = sqlScanDiscard
+		case generic || len(col.field.transforms) > 0:
+			col.mode = sqlScanConvert
+		default:
+			col.mode = sqlDirectMode(col.typ)
+		}
+		p.cols[i] = col
+	}
+	for i := range p.meta.fields {
+		f := &p.meta.fields[i]
+		if f.readonly || sqlCovered(p.cols, f.index) {
+			continue
+		}
+		if f.required && p.missing == nil {
+			p.missing = PathError(f.primary, KindInvalid, KindInvalid, nil, ErrEmpty)
+		}
+		if f.defaultValue != "" {
+			p.defaults = append(p.defaults, sqlColumn{mode: sqlScanConvert, index: f.index, path: f.primary, field: f, typ: f.structField.Type})
+		}
+	}
+	if opt.UseCache {
+		actual, _ := sqlPlans.LoadOrStore(key, p)
+		return actual.(*sqlPlan), true, nil
+	}
+	return p, true, nil
+}
+
+func sqlCovered(cols []sqlColumn, index []int) bool {
+	for _, c := range cols {
+		if len(c.index) >= len(index) && slices.Equal(c.index[:len(index)], index) {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlMatchColumn finds the field for column c in t. Columns that match no field directly are
+// tried against nested struct fields by prefix, so user_id reaches User.ID.
+func sqlMatchColumn(t reflect.Type, c string, prefix []int, path string, opt DTOOptions, depth int) (sqlColumn, bool) {
+	if opt.MaxDepth > 0 && depth > opt.MaxDepth {
+		return sqlColumn{}, false
+	}
+	meta := dtoMetaFor(t, opt)
+	for pass := 0; pass < 2; pass++ {
+		for i := range meta.fields {
+			f := &meta.fields[i]
+			for _, n := range f.names {
+				if n == c || pass == 1 && strings.EqualFold(n, c) {
+					return sqlColumn{index: append(append([]int(nil), prefix...), f.index...), path: joinPath(path, f.primary), field: f, typ: f.structField.Type}, true
+				}
+			}
+		}
+		if pass == 0 && !opt.TagPolicy.CaseInsensitive {
+			break
+		}
+	}
+	for i := range meta.fields {
+		f := &meta.fields[i]
+		ft := indirectType(f.structField.Type)
+		if ft.Kind() != reflect.Struct || !sqlDescend(ft) {
+			continue
+		}
+		for _, n := range f.names {
+			if len(c) <= len(n)+1 || c[len(n)] != '_' || !(c[:len(n)] == n || opt.TagPolicy.CaseInsensitive && strings.EqualFold(c[:len(n)], n)) {
+				continue
+			}
+			idx := append(append([]int(nil), prefix...), f.index...)
+			if col, ok := sqlMatchColumn(ft, c[len(n)+1:], idx, joinPath(path, f.primary), opt, depth+1); ok {
+				return col, true
+			}
+		}
+	}
+	return sqlColumn{}, false
+}
+
+// sqlDescend reports whether a struct type is bound field by field rather than as a value.
+func sqlDescend(t reflect.Type) bool {
+	return t != reflect.TypeOf(time.Time{}) && !reflect.PointerTo(t).Implements(scannerType) && !selfEncoding(t)
+}
+
+func sqlDirectMode(t reflect.Type) sqlScanMode {
+	if reflect.PointerTo(t).Implements(scannerType) {
+		return sqlScanDirect
+	}
+	if t.Kind() == reflect.Pointer {
+		if sqlDirectMode(t.Elem()) != sqlScanConvert {
+			return sqlScanDirect
+		}
+		return sqlScanConvert
+	}
+	if t.PkgPath() != "" {
+		return sqlScanConvert
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return sqlScanNull
+	}
+	return sqlScanConvert
+}
+
+// sqlPlanScanner scans rows through a plan, reusing its temporaries between rows.
+type sqlPlanScanner struct {
+	plan  *sqlPlan
+	opt   DTOOptions
+	dest  []any
+	temps []reflect.Value
+}
+
+func newSQLPlanScanner(p *sqlPlan, opt DTOOptions) *sqlPlanScanner {
+	s := &sqlPlanScanner{plan: p, opt: opt, dest: make([]any, len(p.cols)), temps: make([]reflect.Value, len(p.cols))}
+	for i, c := range p.cols {
+		switch c.mode {
+		case sqlScanNull:
+			s.temps[i] = reflect.New(reflect.PointerTo(c.typ))
+		case sqlScanConvert, sqlScanDiscard:
+			s.temps[i] = reflect.New(reflect.TypeOf((*any)(nil)).Elem())
+		}
+	}
+	return s
+}
+
+func (s *sqlPlanScanner) scan(row SQLScanner, dst reflect.Value) error {
+	if s.plan.missing != nil {
+		return s.plan.missing
+	}
+	for i, c := range s.plan.cols {
+		if c.mode == sqlScanDirect {
+			s.dest[i] = fieldByIndex(dst, c.index).Addr().Interface()
+			continue
+		}
+		s.temps[i].Elem().SetZero()
+		s.dest[i] = s.temps[i].Interface()
+	}
+	if err := row.Scan(s.dest...); err != nil {
+		return err
+	}
+	for i, c := range s.plan.cols {
+		switch c.mode {
+		case sqlScanNull:
+			if p := s.temps[i].Elem(); !p.IsNil() {
+				fieldByIndex(dst, c.index).Set(p.Elem())
+			}
+		case sqlScanConvert:
+			raw := s.temps[i].Elem().Interface()
+			v, err := NormalizeDriverValue(raw, c.typ)
+			if err != nil {
+				return markSensitive(PathError(c.path, KindOf(raw), KindInvalid, raw, err), c.field.sensitive)
+			}
+			if v == nil {
+				break
+			}
+			if err := s.set(dst, c, applyDTOTransforms(v, c.field.transforms)); err != nil {
+				return err
+			}
+		}
+		if c.mode != sqlScanDiscard && c.field.validate {
+			if err := validateReflectField(fieldByIndex(dst, c.index), c.field.structField, c.path); err != nil {
+				return markSensitive(err, c.field.sensitive)
+			}
+		}
+	}
+	for _, c := range s.plan.defaults {
+		def, err := expandDefault(c.field.defaultValue, nil, s.plan.meta, s.opt)
+		if err != nil {
+			return PathError(c.path, KindString, KindInvalid, c.field.defaultValue, err)
+		}
+		if err := s.set(dst, c, def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlPlanScanner) set(dst reflect.Value, c sqlColumn, v any) error {
+	if err := dtoSet(fieldByIndex(dst, c.index), v, c.path, s.opt); err != nil {
+		return markSensitive(err, c.field.sensitive)
+	}
+	return nil
+}
+
+// JSON stores Data in a JSON column. It scans from JSON text or bytes, NULL leaves Data at
+// its zero value, and it encodes as JSON text. It marshals as Data itself, so it can be
+// used in API DTOs as well.
+type JSON[T any] struct{ Data T }
+
+func (j *JSON[T]) Scan(src any) error {
+	var zero T
+	j.Data = zero
+	switch x := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(x, &j.Data)
+	case string:
+		return json.Unmarshal([]byte(x), &j.Data)
+	}
+	return PathError("", KindOf(src), KindBytes, src, ErrUnsupported)
+}
+func (j JSON[T]) Value() (driver.Value, error) {
+	b, err := json.Marshal(j.Data)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+func (j JSON[T]) MarshalJSON() ([]byte, error)  { return json.Marshal(j.Data) }
+func (j *JSON[T]) UnmarshalJSON(b []byte) error { return json.Unmarshal(b, &j.Data) }
+
+// Array is a Postgres array column such as text[] or int[]. It scans from the array literal
+// {a,"b c",NULL}, converting elements with ToSlice, and encodes back to a literal. NULL
+// elements scan as zero values; a NULL column scans as a nil Array.
+type Array[T Target] []T
+
+func (a *Array[T]) Scan(src any) error {
+	var s string
+	switch x := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		s = string(x)
+	case string:
+		s = x
+	default:
+		return PathError("", KindOf(src), KindSlice, src, ErrUnsupported)
+	}
+	items, err := parsePGArray(s)
+	if err != nil {
+		return PathError("", KindString, KindSlice, s, err)
+	}
+	var zero T
+	for i, v := range items {
+		if v == nil {
+			items[i] = zero
+		}
+	}
+	out, err := ToSlice[T](items)
+	if err != nil {
+		return PathError("", KindString, KindSlice, s, err)
+	}
+	*a = out
+	return nil
+}
+func (a Array[T]) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, v := range a {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		s, err := ToString(v)
+		if err != nil {
+			return nil, PathError(indexPath("", i), KindOf(v), KindString, v, err)
+		}
+		writePGArrayElem(&b, s)
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// parsePGArray splits a one-dimensional Postgres array literal into its elements; unquoted
+// NULL becomes nil.
+func parsePGArray(s string) ([]any, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, errf("%w: array literal must be enclosed in braces", ErrInvalid)
+	}
+	body := s[1 : len(s)-1]
+	out := []any{}
+	if strings.TrimSpace(body) == "" {
+		return out, nil
+	}
+	for i := 0; ; {
+		for i < len(body) && body[i] == ' ' {
+			i++
+		}
+		var elem strings.Builder
+		quoted := i < len(body) && body[i] == '"'
+		if quoted {
+			i++
+			for ; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				}
+				elem.WriteByte(body[i])
+			}
+			if i >= len(body) {
+				return nil, errf("%w: unterminated quoted array element", ErrInvalid)
+			}
+			i++
+		} else {
+			for ; i < len(body) && body[i] != ','; i++ {
+				if body[i] == '{' || body[i] == '"' {
+					return nil, errf("%w: only one-dimensional arrays are supported", ErrUnsupported)
+				}
+				elem.WriteByte(body[i])
+			}
+		}
+		v := strings.TrimSpace(elem.String())
+		if quoted {
+			out = append(out, elem.String())
+		} else if strings.EqualFold(v, "NULL") {
+			out = append(out, nil)
+		} else {
+			out = append(out, v)
+		}
+		for i < len(body) && body[i] == ' ' {
+			i++
+		}
+		if i >= len(body) {
+			return out, nil
+		}
+		if body[i] != ',' {
+			return nil, errf("%w: unexpected %q in array literal", ErrInvalid, body[i])
+		}
+		i++
+	}
+}
+func writePGArrayElem(b *strings.Builder, s string) {
+	if s != "" && !strings.EqualFold(s, "NULL") && !strings.ContainsAny(s, "{},\"\\ \t\n\r") {
+		b.WriteString(s)
+		return
+	}
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+}
+
+// Scan implements sql.Scanner: NULL clears Valid, anything else is converted into Value.
+func (n *Nullable[T]) Scan(src any) error {
+	var zero T
+	n.Value, n.Valid = zero, false
+	if src == nil {
+		return nil
+	}
+	if b, ok := src.([]byte); ok {
+		src = string(b)
+	}
+	if err := DTO(&n.Value, src); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// driverValuer is implemented by types that cannot be a driver.Valuer because they have a
+// field named Value, such as Nullable. ToDriverValue and ToSQLArgs use it.
+type driverValuer interface{ driverValue() (driver.Value, error) }
+
+func (n Nullable[T]) driverValue() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return ToDriverValue(n.Value)
+}
+
+// Valuer returns n as a driver.Valuer for database/sql, e.g. db.Exec(q, n.Valuer()).
+func (n Nullable[T]) Valuer() driver.Valuer { return valuerFunc(n.driverValue) }
+
+func (o Option[T]) driverValue() (driver.Value, error) {
+	if !o.OK {
+		return nil, nil
+	}
+	return ToDriverValue(o.Value)
+}
+
+// Valuer returns o as a driver.Valuer; an Option without a value is written as NULL.
+func (o Option[T]) Valuer() driver.Valuer { return valuerFunc(o.driverValue) }
+
+// driverValue writes an unset or null Optional as NULL.
+func (o Optional[T]) driverValue() (driver.Value, error) {
+	if !o.Set || o.Null {
+		return nil, nil
+	}
+	return ToDriverValue(o.Value)
+}
+
+type valuerFunc func() (driver.Value, error)
+
+func (f valuerFunc) Value() (driver.Value, error) { return f() }
+
+// SQLDialect selects placeholder syntax and, for UpsertSQL, the conflict clause.
+type SQLDialect int
+
+const (
+	DialectPostgres  SQLDialect = iota // $1, ON CONFLICT
+	DialectMySQL                       // ?, ON DUPLICATE KEY UPDATE
+	DialectSQLite                      // ?, ON CONFLICT
+	DialectSQLServer                   // @p1
+)
+
+// ErrUnknownParam is returned by BindNamed for a placeholder with no matching field or key.
+var ErrUnknownParam = errors.New("convert: unknown named parameter")
+
+func (d SQLDialect) placeholder(n int) string {
+	switch d {
+	case DialectPostgres:
+		return "$" + strconv.Itoa(n)
+	case DialectSQLServer:
+		return "@p" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// BindNamed rewrites :name and @name placeholders in query into the dialect's positional
+// form and returns the matching arguments from src, a struct or map. Struct names resolve
+// through db tags, and a.b reaches nested fields. A slice value expands into one
+// placeholder per element, for IN (:ids). String literals, quoted identifiers, comments,
+// Postgres dollar-quoted bodies and :: casts are left alone.
+func BindNamed(query string, src any, dialect SQLDialect, opts ...DTOOption) (string, []any, error) {
+	opt := dtoOptionsFrom(append([]DTOOption{WithDTOTags("db", "json", "convert")}, opts...))
+	return bindNamed(query, reflect.ValueOf(src), dialect, opt, nil)
+}
+
+// bindNamed is BindNamed appending to args, so placeholders are numbered after them.
+func bindNamed(query string, rv reflect.Value, dialect SQLDialect, opt DTOOptions, args []any) (string, []any, error) {
+	var b strings.Builder
+	seen := map[string]string{}
+	for i := 0; i < len(query); {
+		c := query[i]
+		if end := sqlSkipQuoted(query, i, dialect); end > i {
+			b.WriteString(query[i:end])
+			i = end
+			continue
+		}
+		if (c == ':' || c == '@') && i+1 < len(query) && query[i+1] == c {
+			b.WriteString(query[i : i+2])
+			i += 2
+			continue
+		}
+		if (c != ':' && c != '@') || i+1 >= len(query) || !sqlNameStart(query[i+1]) || i > 0 && sqlNamePart(query[i-1]) {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(query) && (sqlNamePart(query[j]) || query[j] == '.' && j+1 < len(query) && sqlNameStart(query[j+1])) {
+			j++
+		}
+		name := query[i+1 : j]
+		i = j
+		if ph, ok := seen[name]; ok && dialect != DialectMySQL && dialect != DialectSQLite {
+			b.WriteString(ph)
+			continue
+		}
+		v, ok := sqlNamedValue(rv, name, opt)
+		if !ok {
+			return "", nil, PathError(name, KindInvalid, KindInvalid, nil, ErrUnknownParam)
+		}
+		items, expand, err := sqlExpand(v, name)
+		if err != nil {
+			return "", nil, err
+		}
+		start := b.Len()
+		for k, item := range items {
+			if k > 0 {
+				b.WriteString(", ")
+			}
+			args = append(args, item)
+			b.WriteString(dialect.placeholder(len(args)))
+		}
+		if !expand {
+			seen[name] = b.String()[start:]
+		}
+	}
+	return b.String(), args, nil
+}
+
+// sqlSkipQuoted returns the end of a literal, quoted identifier or comment starting at i,
+// or i if there is none. Postgres $tag$ dollar quotes and MySQL backslash escapes inside
+// string literals are honoured for their dialects.
+func sqlSkipQuoted(q string, i int, dialect SQLDialect) int {
+	switch c := q[i]; {
+	case c == '$' && dialect == DialectPostgres && (i == 0 || !sqlNamePart(q[i-1])):
+		j := i + 1
+		for j < len(q) && (j == i+1 && sqlNameStart(q[j]) || j > i+1 && sqlNamePart(q[j])) {
+			j++
+		}
+		if j >= len(q) || q[j] != '$' {
+			return i
+		}
+		tag := q[i : j+1]
+		if end := strings.Index(q[j+1:], tag); end >= 0 {
+			return j + 1 + end + len(tag)
+		}
+		return len(q)
+	case c == '\'' || c == '"' || c == '`':
+		for j := i + 1; j < len(q); j++ {
+			if q[j] == '\\' && c != '`' && dialect == DialectMySQL {
+				j++
+				continue
+			}
+			if q[j] == c {
+				if j+1 < len(q) && q[j+1] == c {
+					j++
+					continue
+				}
+				return j + 1
+			}
+		}
+		return len(q)
+	case c == '-' && strings.HasPrefix(q[i:], "--"):
+		if end := strings.IndexByte(q[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(q)
+	case c == '/' && strings.HasPrefix(q[i:], "/*"):
+		if end := strings.Index(q[i+2:], "*/"); end >= 0 {
+			return i + 2 + end + 2
+		}
+		return len(q)
+	}
+	return i
+}
+func sqlNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+func sqlNamePart(c byte) bool { return sqlNameStart(c) || c >= '0' && c <= '9' }
+
+// sqlNamedValue resolves a dotted name against a struct or string-keyed map.
+func sqlNamedValue(v reflect.Value, name string, opt DTOOptions) (reflect.Value, bool) {
+	for _, part := range strings.Split(name, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, false
+			}
+			mv := v.MapIndex(reflect.ValueOf(part).Convert(v.Type().Key()))
+			if !mv.IsValid() && opt.TagPolicy.CaseInsensitive {
+				for it := v.MapRange(); it.Next(); {
+					if strings.EqualFold(it.Key().String(), part) {
+						mv = it.Value()
+						break
+					}
+				}
+			}
+			if !mv.IsValid() {
+				return reflect.Value{}, false
+			}
+			v = mv
+		case reflect.Struct:
+			f, ok := sqlFieldNamed(v.Type(), part, opt)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			if v = fieldByIndex(v, f.index); !v.IsValid() {
+				return reflect.Value{}, false
+			}
+		default:
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+func sqlFieldNamed(t reflect.Type, name string, opt DTOOptions) (dtoFieldMeta, bool) {
+	meta := dtoMetaFor(t, opt)
+	for _, f := range meta.fields {
+		if slices.Contains(f.names, name) {
+			return f, true
+		}
+	}
+	if opt.TagPolicy.CaseInsensitive {
+		for _, f := range meta.fields {
+			for _, n := range f.names {
+				if strings.EqualFold(n, name) {
+					return f, true
+				}
+			}
+		}
+	}
+	return dtoFieldMeta{}, false
+}
+
+// sqlExpand returns the argument list for v: its elements for a slice or array, else v
+// itself as a driver argument.
+func sqlExpand(v reflect.Value, name string) ([]any, bool, error) {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() == reflect.Interface {
+		return []any{nil}, false, nil
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 &&
+		!v.Type().Implements(valuerType) && !reflect.PointerTo(v.Type()).Implements(scannerType) {
+		if v.Len() == 0 {
+			return nil, true, PathError(name, KindSlice, KindInvalid, nil, errf("%w: empty list", ErrEmpty))
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			arg, err := sqlArg(v.Index(i), indexPath(name, i))
+			if err != nil {
+				return nil, true, err
+			}
+			out[i] = arg
+		}
+		return out, true, nil
+	}
+	arg, err := sqlArg(v, name)
+	return []any{arg}, false, err
+}
+func sqlArg(v reflect.Value, path string) (any, error) {
+	x := v.Interface()
+	if dv, ok := x.(driverValuer); ok {
+		out, err := dv.driverValue()
+		if err != nil {
+			return nil, PathError(path, KindOf(x), KindInvalid, x, err)
+		}
+		return out, nil
+	}
+	return x, nil
+}
+
+// sqlWriteColumn is one column of a row to be written.
+type sqlWriteColumn struct {
+	name  string
+	pk    bool
+	value any
+	skip  bool // omitempty zero value or unset Optional: use the column default
+}
+
+// sqlWriteColumns lists the columns InsertSQL, UpdateSQL and UpsertSQL write for a struct:
+// every db-tagged field except readonly ones, with pk marking the key.
+func sqlWriteColumns(v reflect.Value, opt DTOOptions) ([]sqlWriteColumn, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, ErrNil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, PathError("", KindOfReflect(v), KindStruct, nil, ErrUnsupported)
+	}
+	var out []sqlWriteColumn
+	for _, f := range dtoMetaFor(v.Type(), opt).fields {
+		fv := fieldByIndex(v, f.index)
+		if f.readonly || !fv.IsValid() || !fv.CanInterface() {
+			continue
+		}
+		c := sqlWriteColumn{name: f.primary, pk: hasTagOption(f.structField, "pk")}
+		switch {
+		case isOptionalType(fv.Type()) && !fv.Field(1).Bool():
+			c.skip = true
+		case hasTagOption(f.structField, "omitempty") && fv.IsZero():
+			c.skip = true
+		default:
+			arg, err := sqlArg(fv, f.primary)
+			if err != nil {
+				return nil, err
+			}
+			c.value = arg
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (d SQLDialect) quote(ident string) string {
+	parts := strings.Split(ident, ".")
+	for i, p := range parts {
+		switch d {
+		case DialectMySQL:
+			parts[i] = "`" + strings.ReplaceAll(p, "`", "``") + "`"
+		case DialectSQLServer:
+			parts[i] = "[" + strings.ReplaceAll(p, "]", "]]") + "]"
+		default:
+			parts[i] = `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// InsertSQL builds a multi-row INSERT for rows. readonly fields are never written. A column
+// that is omitempty and zero, or an unset Optional, in every row is left out; in the other
+// rows it is written as DEFAULT, or as NULL on SQLite, which has no DEFAULT value keyword.
+func InsertSQL[T any](dialect SQLDialect, table string, rows ...T) (string, []any, error) {
+	q, args, _, err := insertSQL(dialect, table, rows, nil)
+	return q, args, err
+}
+
+func insertSQL[T any](dialect SQLDialect, table string, rows []T, opts []DTOOption) (string, []any, []sqlWriteColumn, error) {
+	if len(rows) == 0 {
+		return "", nil, nil, PathError(table, KindSlice, KindStruct, nil, errf("%w: no rows", ErrEmpty))
+	}
+	opt := dtoOptionsFrom(append([]DTOOption{WithDTOTags("db", "json", "convert")}, opts...))
+	all := make([][]sqlWriteColumn, len(rows))
+	var keep []bool
+	for i, r := range rows {
+		cols, err := sqlWriteColumns(reflect.ValueOf(r), opt)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if i > 0 && len(cols) != len(keep) {
+			return "", nil, nil, PathError(indexPath("", i), KindStruct, KindStruct, nil, errf("%w: rows have different columns", ErrUnsupported))
+		}
+		all[i] = cols
+		if keep == nil {
+			keep = make([]bool, len(cols))
+		}
+		for j, c := range cols {
+			keep[j] = keep[j] || !c.skip
+		}
+	}
+	var used []sqlWriteColumn
+	var at []int
+	for j, k := range keep {
+		if k {
+			used, at = append(used, all[0][j]), append(at, j)
+		}
+	}
+	if len(used) == 0 {
+		return "", nil, nil, PathError(table, KindStruct, KindInvalid, nil, errf("%w: no columns to insert", ErrEmpty))
+	}
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + dialect.quote(table) + " (")
+	for i, c := range used {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(dialect.quote(c.name))
+	}
+	b.WriteString(") VALUES ")
+	var args []any
+	for i, cols := range all {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j, k := range at {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			c := cols[k]
+			switch {
+			case !c.skip:
+				args = append(args, c.value)
+				b.WriteString(dialect.placeholder(len(args)))
+			case dialect == DialectSQLite:
+				b.WriteString("NULL")
+			default:
+				b.WriteString("DEFAULT")
+			}
+		}
+		b.WriteByte(')')
+	}
+	return b.String(), args, used, nil
+}
+
+// UpdateSQL builds an UPDATE of table from src, a struct. Only fields that were sent are set:
+// readonly fields, pk fields, omitempty zero values and unset Optional fields are skipped,
+// and a null Optional sets NULL. where is a condition with named parameters resolved from src
+// as in BindNamed, such as "id = :id AND tenant = :tenant"; when empty, the pk fields are
+// used and must be set. Optional and Nullable values are bound by their driver value.
+func UpdateSQL(dialect SQLDialect, table string, src any, where string, opts ...DTOOption) (string, []any, error) {
+	opt := dtoOptionsFrom(append([]DTOOption{WithDTOTags("db", "json", "convert")}, opts...))
+	cols, err := sqlWriteColumns(reflect.ValueOf(src), opt)
+	if err != nil {
+		return "", nil, err
+	}
+	var b strings.Builder
+	var args []any
+	var keys []sqlWriteColumn
+	b.WriteString("UPDATE " + dialect.quote(table) + " SET ")
+	for _, c := range cols {
+		if c.pk {
+			keys = append(keys, c)
+			continue
+		}
+		if c.skip {
+			continue
+		}
+		if len(args) > 0 {
+			b.WriteString(", ")
+		}
+		args = append(args, c.value)
+		b.WriteString(dialect.quote(c.name) + " = " + dialect.placeholder(len(args)))
+	}
+	if len(args) == 0 {
+		return "", nil, PathError(table, KindStruct, KindInvalid, nil, errf("%w: no columns to update", ErrEmpty))
+	}
+	if where == "" {
+		if len(keys) == 0 {
+			return "", nil, PathError(table, KindStruct, KindInvalid, nil, errf("%w: no where clause and no pk fields", ErrEmpty))
+		}
+		b.WriteString(" WHERE ")
+		for i, c := range keys {
+			if c.skip {
+				return "", nil, PathError(c.name, KindStruct, KindInvalid, nil, errf("%w: pk field is not set", ErrEmpty))
+			}
+			if i > 0 {
+				b.WriteString(" AND ")
+			}
+			args = append(args, c.value)
+			b.WriteString(dialect.quote(c.name) + " = " + dialect.placeholder(len(args)))
+		}
+		return b.String(), args, nil
+	}
+	cond, args, err := bindNamed(where, reflect.ValueOf(src), dialect, opt, args)
+	if err != nil {
+		return "", nil, err
+	}
+	return b.String() + " WHERE " + cond, args, nil
+}
+
+// UpsertSQL is InsertSQL with a conflict clause on the pk fields that updates every other
+// inserted column: ON CONFLICT on Postgres and SQLite, ON DUPLICATE KEY UPDATE on MySQL.
+// SQL Server is not supported.
+func UpsertSQL[T any](dialect SQLDialect, table string, rows ...T) (string, []any, error) {
+	if dialect == DialectSQLServer {
+		return "", nil, PathError(table, KindStruct, KindInvalid, nil, errf("%w: upsert on SQL Server", ErrUnsupported))
+	}
+	q, args, used, err := insertSQL(dialect, table, rows, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	var keys, sets []string
+	for _, c := range used {
+		name := dialect.quote(c.name)
+		switch {
+		case c.pk:
+			keys = append(keys, name)
+		case dialect == DialectMySQL:
+			sets = append(sets, name+" = VALUES("+name+")")
+		default:
+			sets = append(sets, name+" = EXCLUDED."+name)
+		}
+	}
+	if dialect == DialectMySQL {
+		if len(sets) == 0 {
+			return q, args, nil
+		}
+		return q + " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "), args, nil
+	}
+	if len(keys) == 0 {
+		return "", nil, PathError(table, KindStruct, KindInvalid, nil, errf("%w: no pk fields for the conflict target", ErrEmpty))
+	}
+	q += " ON CONFLICT (" + strings.Join(keys, ", ") + ")"
+	if len(sets) == 0 {
+		return q + " DO NOTHING", args, nil
+	}
+	return q + " DO UPDATE SET " + strings.Join(sets, ", "), args, nil
+}
+
+// NormalizeDriverValue rewrites a value returned by a database/sql driver into the form the
+// converters expect for target, using the textual encodings drivers commonly return:
+//
+//   - numeric text into a type registered with RegisterDecimalAdapter
+//   - Postgres interval text (1 day 02:03:04, or P1DT2H3M) into time.Duration
+//   - bytea hex (\x0a0b) into []byte, and MySQL BIT(1) bytes into bool
+//   - inet and cidr text into netip.Prefix or netip.Addr
+//   - uuid text or 16 raw bytes into a [16]byte type
+//   - json and jsonb text into maps, structs and slices, and array literals into slices
+//   - MySQL zero dates into the zero time.Time, and timestamps with short zone offsets
+//
+// Anything else, including NULL, is returned unchanged except that []byte becomes string.
+// FromSQLRow, FromSQLRows and ScanTo apply it to every column.
+func NormalizeDriverValue(v any, target reflect.Type) (any, error) {
+	b, isBytes := v.([]byte)
+	s, isString := v.(string)
+	if isBytes {
+		s = string(b)
+	}
+	if target == nil || !isBytes && !isString {
+		return v, nil
+	}
+	for target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+	if e, ok := typedDecimal.Load(target); ok {
+		out := reflect.ValueOf(e).FieldByName("Parse").Call([]reflect.Value{reflect.ValueOf(strings.TrimSpace(s))})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		return out[0].Interface(), nil
+	}
+	switch target {
+	case reflect.TypeOf(time.Duration(0)):
+		if d, ok, err := parseInterval(s); ok {
+			return d, err
+		}
+		return s, nil
+	case reflect.TypeOf(time.Time{}):
+		if strings.HasPrefix(s, "0000-00-00") {
+			return time.Time{}, nil
+		}
+		for _, layout := range []string{"2006-01-02 15:04:05.999999999-07", "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999-07:00:00"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return s, nil
+	case reflect.TypeOf(netip.Prefix{}):
+		if !strings.Contains(s, "/") {
+			a, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, err
+			}
+			return netip.PrefixFrom(a, a.BitLen()), nil
+		}
+		return netip.ParsePrefix(s)
+	case reflect.TypeOf(netip.Addr{}):
+		addr, _, _ := strings.Cut(s, "/")
+		return netip.ParseAddr(addr)
+	}
+	switch target.Kind() {
+	case reflect.Bool:
+		if isBytes && len(b) == 1 && b[0] <= 1 {
+			return b[0] == 1, nil
+		}
+	case reflect.Slice:
+		if target.Elem().Kind() == reflect.Uint8 {
+			if strings.HasPrefix(s, `\x`) {
+				return hex.DecodeString(s[2:])
+			}
+			if isBytes {
+				return b, nil
+			}
+			return s, nil
+		}
+		t := strings.TrimSpace(s)
+		if strings.HasPrefix(t, "[") {
+			return decodeJSONColumn(t)
+		}
+		if strings.HasPrefix(t, "{") {
+			return parsePGArray(t)
+		}
+	case reflect.Map, reflect.Struct:
+		if t := strings.TrimSpace(s); strings.HasPrefix(t, "{") {
+			return decodeJSONColumn(t)
+		}
+	case reflect.Array:
+		if target.Len() == 16 && target.Elem().Kind() == reflect.Uint8 {
+			return parseUUIDBytes(b, s, isBytes, target)
+		}
+	}
+	return s, nil
+}
+
+func decodeJSONColumn(s string) (any, error) {
+	var out any
+	if err := json.Unmarshal([]byte(s), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func parseUUIDBytes(b []byte, s string, raw bool, target reflect.Type) (any, error) {
+	out := reflect.New(target).Elem()
+	if raw && len(b) == 16 {
+		reflect.Copy(out, reflect.ValueOf(b))
+		return out.Interface(), nil
+	}
+	h := strings.ReplaceAll(strings.Trim(s, "{}"), "-", "")
+	d, err := hex.DecodeString(h)
+	if err != nil || len(d) != 16 {
+		return nil, errf("%w: not a UUID", ErrInvalid)
+	}
+	reflect.Copy(out, reflect.ValueOf(d))
+	return out.Interface(), nil
+}
+
+// parseInterval parses Postgres interval output in the postgres and iso_8601 styles. ok is
+// false when s is not an interval, so it can fall back to Go duration syntax. Years and
+// months have no fixed length and are rejected.
+func parseInterval(s string) (d time.Duration, ok bool, err error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "P") || strings.HasPrefix(s, "-P") {
+		return parseISOInterval(s)
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, false, nil
+	}
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if strings.Contains(f, ":") {
+			c, err := parseIntervalClock(f)
+			if err != nil {
+				return 0, false, nil
+			}
+			d += c
+			continue
+		}
+		n, err := strconv.ParseInt(f, 10, 64)
+		if err != nil || i+1 >= len(fields) {
+			return 0, false, nil
+		}
+		i++
+		switch strings.TrimSuffix(fields[i], "s") {
+		case "day":
+			d += time.Duration(n) * 24 * time.Hour
+		case "year", "mon":
+			return 0, true, errf("%w: interval %q has months or years, which have no fixed duration", ErrUnsupported, s)
+		default:
+			return 0, false, nil
+		}
+	}
+	return d, true, nil
+}
+func parseIntervalClock(f string) (time.Duration, error) {
+	neg := strings.HasPrefix(f, "-")
+	parts := strings.Split(strings.TrimLeft(f, "+-"), ":")
+	if len(parts) != 3 {
+		return 0, ErrInvalid
+	}
+	h, err1 := strconv.ParseInt(parts[0], 10, 64)
+	m, err2 := strconv.ParseInt(parts[1], 10, 64)
+	sec, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, ErrInvalid
+	}
+	d := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec*float64(time.Second))
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+func parseISOInterval(s string) (time.Duration, bool, error) {
+	neg := strings.HasPrefix(s, "-")
+	rest := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "P")
+	var d time.Duration
+	inTime := false
+	for rest != "" {
+		if rest[0] == 'T' {
+			inTime, rest = true, rest[1:]
+			continue
+		}
+		i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != '-' })
+		if i <= 0 {
+			return 0, false, nil
+		}
+		n, err := strconv.ParseFloat(rest[:i], 64)
+		if err != nil {
+			return 0, false, nil
+		}
+		unit := rest[i]
+		rest = rest[i+1:]
+		switch {
+		case unit == 'W' && !inTime:
+			d += time.Duration(n * float64(7*24*time.Hour))
+		case unit == 'D' && !inTime:
+			d += time.Duration(n * float64(24*time.Hour))
+		case unit == 'Y' || unit == 'M' && !inTime:
+			return 0, true, errf("%w: interval %q has months or years, which have no fixed duration", ErrUnsupported, s)
+		case unit == 'H' && inTime:
+			d += time.Duration(n * float64(time.Hour))
+		case unit == 'M' && inTime:
+			d += time.Duration(n * float64(time.Minute))
+		case unit == 'S' && inTime:
+			d += time.Duration(n * float64(time.Second))
+		default:
+			return 0, false, nil
+		}
+	}
+	if neg {
+		d = -d
+	}
+	return d, true, nil
+}
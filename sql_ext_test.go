@@ -0,0 +1,452 @@
+package convert
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sqlTestDriver serves canned results keyed by query text, so rows go through the real
+// database/sql conversion rules.
+type sqlTestDriver struct{}
+type sqlTestConn struct{}
+type sqlTestStmt struct{ query string }
+type sqlTestResult struct {
+	cols []string
+	rows [][]driver.Value
+}
+type sqlTestRows struct {
+	sqlTestResult
+	next int
+}
+
+var (
+	sqlTestResults sync.Map // map[string]sqlTestResult
+	sqlTestSeq     atomic.Int64
+	sqlTestDB      = func() *sql.DB {
+		sql.Register("convert_test", sqlTestDriver{})
+		db, _ := sql.Open("convert_test", "")
+		return db
+	}()
+)
+
+func (sqlTestDriver) Open(string) (driver.Conn, error)    { return sqlTestConn{}, nil }
+func (sqlTestConn) Prepare(q string) (driver.Stmt, error) { return sqlTestStmt{q}, nil }
+func (sqlTestConn) Close() error                          { return nil }
+func (sqlTestConn) Begin() (driver.Tx, error)             { return nil, errors.New("no transactions") }
+func (sqlTestStmt) Close() error                          { return nil }
+func (sqlTestStmt) NumInput() int                         { return -1 }
+func (sqlTestStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+func (s sqlTestStmt) Query([]driver.Value) (driver.Rows, error) {
+	r, _ := sqlTestResults.Load(s.query)
+	return &sqlTestRows{sqlTestResult: r.(sqlTestResult)}, nil
+}
+func (r *sqlTestRows) Columns() []string { return r.cols }
+func (r *sqlTestRows) Close() error      { return nil }
+func (r *sqlTestRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func sqlTestQuery(t testing.TB, cols []string, rows ...[]driver.Value) *sql.Rows {
+	t.Helper()
+	q := "q" + strconv.FormatInt(sqlTestSeq.Add(1), 10)
+	sqlTestResults.Store(q, sqlTestResult{cols: cols, rows: rows})
+	rs, err := sqlTestDB.Query(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+type sqlTestAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+type sqlTestUser struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+}
+type sqlTestOrder struct {
+	sqlTestAudit
+	ID       int64         `db:"id"`
+	Note     *string       `db:"note"`
+	Total    float64       `db:"total"`
+	Paid     bool          `db:"paid"`
+	Placed   time.Time     `db:"placed_at"`
+	TTL      time.Duration `db:"ttl"`
+	Status   sqlTestStatus `db:"status"`
+	Ref      sql.NullInt64 `db:"ref"`
+	Code     string        `db:"code,trim,upper"`
+	Source   string        `db:"source" default:"web"`
+	Version  int           `db:"version,readonly"`
+	User     sqlTestUser   `db:"user"`
+	Customer *sqlTestUser  `db:"customer"`
+}
+type sqlTestStatus string
+
+func TestFromSQLRowsPlan(t *testing.T) {
+	placed := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	cols := []string{"id", "note", "total", "paid", "placed_at", "ttl", "status", "ref", "code", "version", "user_id", "USER_NAME", "customer_id", "created_by", "extra"}
+	rows := sqlTestQuery(t, cols,
+		[]driver.Value{int64(1), "gift", "12.5", true, placed, "90s", []byte("open"), int64(7), []byte(" ab "), int64(3), int64(10), "Ann", int64(20), "ops", "x"},
+		[]driver.Value{int64(2), nil, 3.0, "f", placed, nil, nil, nil, "c", nil, int64(11), nil, nil, nil, nil},
+	)
+	got, err := FromSQLRows[sqlTestOrder](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("rows = %d", len(got))
+	}
+	a, b := got[0], got[1]
+	if a.ID != 1 || a.Note == nil || *a.Note != "gift" || a.Total != 12.5 || !a.Paid || !a.Placed.Equal(placed) || a.TTL != 90*time.Second ||
+		a.Status != "open" || !a.Ref.Valid || a.Ref.Int64 != 7 || a.Code != "AB" || a.Source != "web" || a.Version != 0 ||
+		a.User != (sqlTestUser{ID: 10, Name: "Ann"}) || a.Customer == nil || a.Customer.ID != 20 || a.CreatedBy != "ops" {
+		t.Fatalf("row 1 = %+v", a)
+	}
+	if b.ID != 2 || b.Note != nil || b.Total != 3 || b.Paid || b.TTL != 0 || b.Status != "" || b.Ref.Valid || b.User.ID != 11 || b.User.Name != "" {
+		t.Fatalf("row 2 = %+v", b)
+	}
+
+	if _, err := FromSQLRows[sqlTestOrder](sqlTestQuery(t, []string{"id", "extra"})); err != nil {
+		t.Fatalf("lenient plan: %v", err)
+	}
+	_, err = FromSQLRows[sqlTestOrder](sqlTestQuery(t, []string{"id", "extra"}), WithDTOErrorUnused())
+	var detail *ErrorDetail
+	if !errors.As(err, &detail) || detail.Path != "extra" || !errors.Is(err, ErrUnusedField) {
+		t.Fatalf("unused column: %v", err)
+	}
+	_, err = FromSQLRows[sqlTestOrder](sqlTestQuery(t, []string{"id"}, []driver.Value{"x"}))
+	if err == nil {
+		t.Fatal("expected scan error")
+	}
+
+	type required struct {
+		ID   int    `db:"id"`
+		Name string `db:"name" validate:"required"`
+	}
+	if _, err := FromSQLRows[required](sqlTestQuery(t, []string{"id"})); err != nil {
+		t.Fatalf("no rows: %v", err)
+	}
+	if _, err := FromSQLRows[required](sqlTestQuery(t, []string{"id"}, []driver.Value{int64(1)})); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("missing required column: %v", err)
+	}
+
+	row := sqlTestDB.QueryRow(func() string {
+		q := "row"
+		sqlTestResults.Store(q, sqlTestResult{cols: []string{"id", "name"}, rows: [][]driver.Value{{int64(5), "Bo"}}})
+		return q
+	}())
+	u, err := FromSQLRow[sqlTestUser](row, []string{"id", "name"})
+	if err != nil || u != (sqlTestUser{ID: 5, Name: "Bo"}) {
+		t.Fatalf("FromSQLRow = %+v %v", u, err)
+	}
+	m, err := FromSQLRows[map[string]any](sqlTestQuery(t, []string{"id"}, []driver.Value{[]byte("9")}))
+	if err != nil || m[0]["id"] != "9" {
+		t.Fatalf("map rows = %v %v", m, err)
+	}
+}
+
+func BenchmarkFromSQLRowsPlan(b *testing.B) {
+	cols := []string{"id", "name", "total", "paid", "placed_at"}
+	row := []driver.Value{int64(1), "Ann", 12.5, true, time.Now()}
+	data := make([][]driver.Value, 1000)
+	for i := range data {
+		data[i] = row
+	}
+	type rec struct {
+		ID     int64     `db:"id"`
+		Name   string    `db:"name"`
+		Total  float64   `db:"total"`
+		Paid   bool      `db:"paid"`
+		Placed time.Time `db:"placed_at"`
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := FromSQLRows[rec](sqlTestQuery(b, cols, data...)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSQLWrappers(t *testing.T) {
+	type settings struct {
+		Theme string   `json:"theme"`
+		Tags  []string `json:"tags"`
+	}
+	type record struct {
+		ID       int               `db:"id"`
+		Settings JSON[settings]    `db:"settings"`
+		Labels   Array[string]     `db:"labels"`
+		Scores   Array[int]        `db:"scores"`
+		Nick     Nullable[string]  `db:"nick"`
+		Age      Nullable[int]     `db:"age"`
+		Extra    *JSON[[]settings] `db:"extra"`
+	}
+	rows := sqlTestQuery(t, []string{"id", "settings", "labels", "scores", "nick", "age", "extra"},
+		[]driver.Value{int64(1), []byte(`{"theme":"dark","tags":["a"]}`), `{plain,"with space","quo\"te",NULL}`, []byte("{1, 2 ,3}"), "bo", []byte("42"), nil},
+		[]driver.Value{int64(2), nil, "{}", nil, nil, nil, `[{"theme":"x"}]`},
+	)
+	got, err := FromSQLRows[record](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := got[0], got[1]
+	if a.Settings.Data.Theme != "dark" || len(a.Settings.Data.Tags) != 1 || !reflect.DeepEqual(a.Labels, Array[string]{"plain", "with space", `quo"te`, ""}) ||
+		!reflect.DeepEqual(a.Scores, Array[int]{1, 2, 3}) || a.Nick != (Nullable[string]{Value: "bo", Valid: true}) || a.Age.Value != 42 || a.Extra != nil {
+		t.Fatalf("row 1 = %+v", a)
+	}
+	if b.Settings.Data.Theme != "" || b.Labels == nil || len(b.Labels) != 0 || b.Scores != nil || b.Nick.Valid || b.Age.Valid || b.Extra == nil || b.Extra.Data[0].Theme != "x" {
+		t.Fatalf("row 2 = %+v", b)
+	}
+
+	for _, c := range []struct {
+		in   any
+		want driver.Value
+	}{
+		{a.Settings, `{"theme":"dark","tags":["a"]}`},
+		{Array[string]{"a", "b c", `x"y`, "", "null"}, `{a,"b c","x\"y","","null"}`},
+		{Array[float64]{1.5, 2}, `{1.5,2.0}`},
+		{Array[int](nil), nil},
+		{Nullable[int]{Value: 3, Valid: true}, int64(3)},
+		{Nullable[string]{}, nil},
+		{Nullable[int]{Value: 3, Valid: true}.Valuer(), int64(3)},
+		{Option[string]{Value: "x", OK: true}.Valuer(), "x"},
+		{Option[string]{Value: "x"}.Valuer(), nil},
+	} {
+		v, err := ToDriverValue(c.in)
+		if err != nil || v != c.want {
+			t.Fatalf("ToDriverValue(%v) = %#v %v, want %#v", c.in, v, err, c.want)
+		}
+	}
+	if v, err := driver.DefaultParameterConverter.ConvertValue(a.Nick.Valuer()); err != nil || v != "bo" {
+		t.Fatalf("Nullable.Valuer through database/sql conversion = %#v %v", v, err)
+	}
+	args, err := ToSQLArgs(a, "id", "nick", "labels")
+	if err != nil || args[0] != 1 || args[1] != "bo" {
+		t.Fatalf("ToSQLArgs = %#v %v", args, err)
+	}
+	if v, _ := driver.DefaultParameterConverter.ConvertValue(args[2]); v != `{plain,"with space","quo\"te",""}` {
+		t.Fatalf("labels arg = %#v", v)
+	}
+	var arr Array[int]
+	if err := arr.Scan("{{1,2},{3}}"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("nested array: %v", err)
+	}
+	if err := arr.Scan(`{"1}`); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("bad literal: %v", err)
+	}
+	b2, _ := json.Marshal(a.Settings)
+	if string(b2) != `{"theme":"dark","tags":["a"]}` {
+		t.Fatalf("JSON marshal = %s", b2)
+	}
+}
+
+func TestBindNamed(t *testing.T) {
+	type filter struct {
+		Status string           `db:"status"`
+		IDs    []int64          `db:"ids"`
+		Owner  sqlTestUser      `db:"owner"`
+		Nick   Nullable[string] `db:"nick"`
+		Tags   Array[string]    `db:"tags"`
+	}
+	src := filter{Status: "open", IDs: []int64{3, 4}, Owner: sqlTestUser{ID: 9}, Tags: Array[string]{"a"}}
+	query := `SELECT id, ':skip' AS s, "@x" FROM t -- :comment
+WHERE status = :status AND id IN (:ids) AND owner_id = @owner.id /* :no */ AND created::date > now() AND nick = :nick AND tags = :tags OR status = :Status`
+	for _, c := range []struct {
+		dialect SQLDialect
+		want    string
+	}{
+		{DialectPostgres, `SELECT id, ':skip' AS s, "@x" FROM t -- :comment
+WHERE status = $1 AND id IN ($2, $3) AND owner_id = $4 /* :no */ AND created::date > now() AND nick = $5 AND tags = $6 OR status = $7`},
+		{DialectMySQL, `SELECT id, ':skip' AS s, "@x" FROM t -- :comment
+WHERE status = ? AND id IN (?, ?) AND owner_id = ? /* :no */ AND created::date > now() AND nick = ? AND tags = ? OR status = ?`},
+		{DialectSQLServer, `SELECT id, ':skip' AS s, "@x" FROM t -- :comment
+WHERE status = @p1 AND id IN (@p2, @p3) AND owner_id = @p4 /* :no */ AND created::date > now() AND nick = @p5 AND tags = @p6 OR status = @p7`},
+	} {
+		q, args, err := BindNamed(query, src, c.dialect)
+		if err != nil || q != c.want {
+			t.Fatalf("%d: %s %v", c.dialect, q, err)
+		}
+		if !reflect.DeepEqual(args, []any{"open", int64(3), int64(4), 9, nil, Array[string]{"a"}, "open"}) {
+			t.Fatalf("args = %#v", args)
+		}
+	}
+	q, args, err := BindNamed("SELECT * FROM t WHERE a = :a OR b = :a AND c IN (:c)", map[string]any{"a": 1, "c": []string{"x"}}, DialectPostgres)
+	if err != nil || q != "SELECT * FROM t WHERE a = $1 OR b = $1 AND c IN ($2)" || !reflect.DeepEqual(args, []any{1, "x"}) {
+		t.Fatalf("map: %s %#v %v", q, args, err)
+	}
+	q, _, err = BindNamed("DO $$ BEGIN PERFORM :x; END $$; SELECT $fn$ :y $fn$, :status", src, DialectPostgres)
+	if err != nil || q != "DO $$ BEGIN PERFORM :x; END $$; SELECT $fn$ :y $fn$, $1" {
+		t.Fatalf("dollar quotes: %s %v", q, err)
+	}
+	q, _, err = BindNamed(`SELECT 'it\'s :x' AS s, :status`, src, DialectMySQL)
+	if err != nil || q != `SELECT 'it\'s :x' AS s, ?` {
+		t.Fatalf("backslash escapes: %s %v", q, err)
+	}
+	if _, _, err := BindNamed("x = :missing", src, DialectMySQL); !errors.Is(err, ErrUnknownParam) {
+		t.Fatalf("missing: %v", err)
+	}
+	if _, _, err := BindNamed("id IN (:ids)", filter{}, DialectMySQL); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("empty list: %v", err)
+	}
+}
+
+func TestWriteSQL(t *testing.T) {
+	type user struct {
+		ID      int64     `db:"id,pk,omitempty"`
+		Email   string    `db:"email"`
+		Name    string    `db:"name,omitempty"`
+		Created time.Time `db:"created_at,readonly"`
+		Meta    JSON[map[string]int]
+	}
+	rows := []user{{Email: "a@x", Name: "Ann"}, {Email: "b@x"}}
+	q, args, err := InsertSQL(DialectPostgres, "public.users", rows...)
+	if err != nil || q != `INSERT INTO "public"."users" ("email", "name", "meta") VALUES ($1, $2, $3), ($4, DEFAULT, $5)` || len(args) != 5 || args[0] != "a@x" || args[3] != "b@x" {
+		t.Fatalf("insert: %s %#v %v", q, args, err)
+	}
+	if q, _, _ = InsertSQL(DialectSQLite, "users", rows...); q != `INSERT INTO "users" ("email", "name", "meta") VALUES (?, ?, ?), (?, NULL, ?)` {
+		t.Fatalf("sqlite insert: %s", q)
+	}
+
+	rows[0].ID, rows[1].ID = 1, 2
+	q, _, err = UpsertSQL(DialectPostgres, "users", rows...)
+	if err != nil || q != `INSERT INTO "users" ("id", "email", "name", "meta") VALUES ($1, $2, $3, $4), ($5, $6, DEFAULT, $7) ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email", "name" = EXCLUDED."name", "meta" = EXCLUDED."meta"` {
+		t.Fatalf("upsert: %s %v", q, err)
+	}
+	q, _, err = UpsertSQL(DialectMySQL, "users", rows[0])
+	if err != nil || q != "INSERT INTO `users` (`id`, `email`, `name`, `meta`) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE `email` = VALUES(`email`), `name` = VALUES(`name`), `meta` = VALUES(`meta`)" {
+		t.Fatalf("mysql upsert: %s %v", q, err)
+	}
+	if _, _, err := UpsertSQL(DialectSQLServer, "users", rows[0]); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("sql server upsert: %v", err)
+	}
+
+	type patch struct {
+		ID     int64            `db:"id,pk"`
+		Tenant string           `db:"tenant"`
+		Email  Optional[string] `db:"email"`
+		Name   Optional[string] `db:"name"`
+		Nick   Optional[string] `db:"nick"`
+	}
+	p := patch{ID: 7, Tenant: "t1", Email: Some("new@x"), Nick: Null[string]()}
+	q, args, err = UpdateSQL(DialectPostgres, "users", p, "")
+	if err != nil || q != `UPDATE "users" SET "tenant" = $1, "email" = $2, "nick" = $3 WHERE "id" = $4` || !reflect.DeepEqual(args, []any{"t1", "new@x", nil, int64(7)}) {
+		t.Fatalf("update: %s %#v %v", q, args, err)
+	}
+	q, args, err = UpdateSQL(DialectSQLServer, "users", p, "id = :id AND tenant = :tenant")
+	if err != nil || q != `UPDATE [users] SET [tenant] = @p1, [email] = @p2, [nick] = @p3 WHERE id = @p4 AND tenant = @p5` || len(args) != 5 {
+		t.Fatalf("update where: %s %#v %v", q, args, err)
+	}
+	type wrapped struct {
+		ID   Optional[int64]            `db:"id,pk"`
+		Org  Nullable[int64]            `db:"org,pk"`
+		Name Optional[Nullable[string]] `db:"name"`
+	}
+	w := wrapped{ID: Some(int64(9)), Org: Nullable[int64]{Value: 3, Valid: true}, Name: Some(Nullable[string]{Value: "x", Valid: true})}
+	q, args, err = UpdateSQL(DialectPostgres, "t", w, "")
+	if err != nil || q != `UPDATE "t" SET "name" = $1 WHERE "id" = $2 AND "org" = $3` || !reflect.DeepEqual(args, []any{"x", int64(9), int64(3)}) {
+		t.Fatalf("wrapped pk: %s %#v %v", q, args, err)
+	}
+	if _, args, err = UpdateSQL(DialectPostgres, "t", w, "id = :id"); err != nil || !reflect.DeepEqual(args, []any{"x", int64(9)}) {
+		t.Fatalf("wrapped named pk: %#v %v", args, err)
+	}
+	if _, args, err = InsertSQL(DialectPostgres, "t", w); err != nil || !reflect.DeepEqual(args, []any{int64(9), int64(3), "x"}) {
+		t.Fatalf("wrapped insert: %#v %v", args, err)
+	}
+	if _, _, err = UpdateSQL(DialectPostgres, "t", wrapped{Name: Some(Nullable[string]{})}, ""); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("unset pk: %v", err)
+	}
+	type noKey struct {
+		Name string `db:"name"`
+	}
+	if _, _, err := UpdateSQL(DialectMySQL, "users", noKey{Name: "x"}, ""); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("update without key: %v", err)
+	}
+	if _, _, err := InsertSQL[user](DialectMySQL, "users"); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("insert without rows: %v", err)
+	}
+}
+
+type sqlTestMoney struct{ cents int64 }
+
+func TestNormalizeDriverValue(t *testing.T) {
+	RegisterDecimalAdapter(DecimalAdapter[sqlTestMoney]{
+		Parse: func(s string) (sqlTestMoney, error) {
+			f, err := strconv.ParseFloat(s, 64)
+			return sqlTestMoney{cents: int64(f*100 + 0.5)}, err
+		},
+		Format: func(m sqlTestMoney) string { return strconv.FormatInt(m.cents, 10) },
+	})
+	defer UnregisterDecimalAdapter[sqlTestMoney]()
+	type uuid [16]byte
+	type row struct {
+		Price   sqlTestMoney   `db:"price"`
+		TTL     time.Duration  `db:"ttl"`
+		Wait    time.Duration  `db:"wait"`
+		Blob    []byte         `db:"blob"`
+		Active  bool           `db:"active"`
+		Flag    bool           `db:"flag"`
+		Net     netip.Prefix   `db:"net"`
+		Host    netip.Addr     `db:"host"`
+		ID      uuid           `db:"id"`
+		Raw     uuid           `db:"raw"`
+		Doc     map[string]any `db:"doc"`
+		Tags    []string       `db:"tags"`
+		List    []int          `db:"list"`
+		Deleted time.Time      `db:"deleted_at"`
+		Seen    *time.Time     `db:"seen_at"`
+	}
+	raw := []byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	rows := sqlTestQuery(t, []string{"price", "ttl", "wait", "blob", "active", "flag", "net", "host", "id", "raw", "doc", "tags", "list", "deleted_at", "seen_at"},
+		[]driver.Value{[]byte("12.34"), []byte("1 day 02:03:04.5"), "PT1H30M", []byte(`\x0aff`), "t", []byte{1}, "10.1.2.3", "10.1.2.3/32",
+			"6ba7b810-9dad-11d1-80b4-00c04fd430c8", raw, []byte(`{"a":1,"b":[true]}`), `{x,"y z"}`, "[1,2]", []byte("0000-00-00 00:00:00"), "2024-05-06 07:08:09.5+02"},
+	)
+	got, err := FromSQLRows[row](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := got[0]
+	want := uuid(raw)
+	if r.Price.cents != 1234 || r.TTL != 26*time.Hour+3*time.Minute+4500*time.Millisecond || r.Wait != 90*time.Minute || !reflect.DeepEqual(r.Blob, []byte{0x0a, 0xff}) ||
+		!r.Active || !r.Flag || r.Net != netip.MustParsePrefix("10.1.2.3/32") || r.Host != netip.MustParseAddr("10.1.2.3") || r.ID != want || r.Raw != want ||
+		r.Doc["a"] != float64(1) || !reflect.DeepEqual(r.Tags, []string{"x", "y z"}) || !reflect.DeepEqual(r.List, []int{1, 2}) || !r.Deleted.IsZero() ||
+		r.Seen == nil || !r.Seen.Equal(time.Date(2024, 5, 6, 5, 8, 9, 5e8, time.UTC)) {
+		t.Fatalf("row = %+v", r)
+	}
+
+	for _, c := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"00:00:01.5", 1500 * time.Millisecond}, {"-1 days +02:00:00", -22 * time.Hour}, {"3 days", 72 * time.Hour}, {"P2DT3S", 48*time.Hour + 3*time.Second}, {"1h5m", time.Hour + 5*time.Minute},
+	} {
+		if d, err := ScanTo[time.Duration]([]byte(c.in)); err != nil || d != c.want {
+			t.Fatalf("interval %q = %v %v", c.in, d, err)
+		}
+	}
+	if _, err := ScanTo[time.Duration]("1 mon 2 days"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("month interval: %v", err)
+	}
+	if b, err := ScanTo[bool]([]byte{0}); err != nil || b {
+		t.Fatalf("BIT(1): %v %v", b, err)
+	}
+	if tm, err := ScanTo[time.Time]("0000-00-00"); err != nil || !tm.IsZero() {
+		t.Fatalf("zero date: %v %v", tm, err)
+	}
+}
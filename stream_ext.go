@@ -0,0 +1,346 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+func dtoBatchOptionsFrom(opts []DTOBatchOption) DTOBatchOptions {
+	bo := DTOBatchOptions{Workers: 4}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&bo)
+		}
+	}
+	return bo
+}
+
+// yieldRecord hands one converted record to yield under the batch error policy: by default
+// the error is yielded and iteration stops, DTOBatchSkipInvalid drops bad records silently
+// and DTOBatchCollectErrors yields each error and carries on. It reports whether to go on.
+func yieldRecord[T any](yield func(T, error) bool, bo DTOBatchOptions, v T, err error) bool {
+	if err == nil {
+		return yield(v, nil)
+	}
+	if bo.SkipInvalid {
+		return true
+	}
+	var zero T
+	return yield(zero, err) && bo.CollectErrors
+}
+
+// RowsSeq converts SQL rows one at a time, like FromSQLRows without holding the result.
+// Record errors carry the row index and follow the DTOBatchOptions error policy; errors
+// from the rows themselves end the sequence. rows is closed when the loop ends if it has a
+// Close method.
+func RowsSeq[T any](rows SQLRows, opts ...DTOBatchOption) iter.Seq2[T, error] {
+	bo := dtoBatchOptionsFrom(opts)
+	return func(yield func(T, error) bool) {
+		if c, ok := rows.(io.Closer); ok {
+			defer c.Close()
+		}
+		sqlRowsSeq[T](rows, bo)(yield)
+	}
+}
+
+func sqlRowsSeq[T any](rows SQLRows, bo DTOBatchOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cols, err := rows.Columns()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		all := append([]DTOOption{WithDTOTags("db", "json", "convert")}, bo.DTOOptions...)
+		opt := dtoOptionsFrom(all)
+		plan, ok, err := sqlPlanFor(reflect.TypeFor[T](), cols, opt)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		var sc *sqlPlanScanner
+		if ok {
+			sc = newSQLPlanScanner(plan, opt)
+		}
+		for i := 0; rows.Next(); i++ {
+			var v T
+			if sc != nil {
+				err = sc.scan(rows, reflect.ValueOf(&v).Elem())
+			} else {
+				vals, ptrs := sqlAnyDest(len(cols))
+				if err = rows.Scan(ptrs...); err == nil {
+					v, err = DTOTo[T](sqlValuesMap(cols, vals), all...)
+				}
+			}
+			if err != nil {
+				err = PathError(indexPath("", i), KindMap, KindStruct, nil, err)
+			}
+			if !yieldRecord(yield, bo, v, err) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// CSVSeq converts CSV records one at a time using the header row and csv tags. Record
+// errors carry the record index and follow the DTOBatchOptions error policy; malformed CSV
+// ends the sequence. Use CSVSeqWith for other dialects.
+func CSVSeq[T any](r io.Reader, opts ...DTOBatchOption) iter.Seq2[T, error] {
+	return CSVSeqWith[T](r, WithCSVBatch(opts...))
+}
+
+// JSONLSeq converts JSON lines one at a time, skipping blank lines. Malformed JSON and
+// conversion errors carry the record index and follow the DTOBatchOptions error policy;
+// read errors end the sequence.
+func JSONLSeq[T any](r io.Reader, opts ...DTOBatchOption) iter.Seq2[T, error] {
+	bo := dtoBatchOptionsFrom(opts)
+	return func(yield func(T, error) bool) {
+		s := bufio.NewScanner(r)
+		s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for i := 0; s.Scan(); {
+			line := strings.TrimSpace(s.Text())
+			if line == "" {
+				continue
+			}
+			var v T
+			var m any
+			err := json.Unmarshal([]byte(line), &m)
+			if err == nil {
+				v, err = DTOTo[T](m, bo.DTOOptions...)
+			}
+			if err != nil {
+				err = PathError(indexPath("", i), KindString, KindStruct, line, err)
+			}
+			i++
+			if !yieldRecord(yield, bo, v, err) {
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// DTOStreamStats reports the work done by DTOStreamJSONLParallel.
+type DTOStreamStats struct {
+	Lines    int64 // lines read, including blank ones
+	Records  int64 // records written
+	BytesIn  int64 // bytes read before decompression
+	Duration time.Duration
+}
+
+// RecordsPerSecond is the output throughput.
+func (s DTOStreamStats) RecordsPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Records) / s.Duration.Seconds()
+}
+
+// DTOStreamOptions configures DTOStreamJSONLParallel.
+type DTOStreamOptions struct {
+	Workers    int  // conversion goroutines; defaults to GOMAXPROCS
+	InFlight   int  // records read but not yet written; defaults to 64 per worker
+	Decompress bool // detect gzip, zstd and registered formats by their magic bytes
+	// CloseOnCancel closes the input, when it is an io.Closer, once ctx is done so a
+	// blocked read returns. The input belongs to the caller, so this is opt-in.
+	CloseOnCancel bool
+	DTOOptions    []DTOOption
+}
+
+type DTOStreamOption func(*DTOStreamOptions)
+
+func WithStreamWorkers(n int) DTOStreamOption  { return func(o *DTOStreamOptions) { o.Workers = n } }
+func WithStreamInFlight(n int) DTOStreamOption { return func(o *DTOStreamOptions) { o.InFlight = n } }
+func WithStreamDecompress() DTOStreamOption    { return func(o *DTOStreamOptions) { o.Decompress = true } }
+func WithStreamCloseOnCancel() DTOStreamOption {
+	return func(o *DTOStreamOptions) { o.CloseOnCancel = true }
+}
+func WithStreamDTOOptions(opts ...DTOOption) DTOStreamOption {
+	return func(o *DTOStreamOptions) { o.DTOOptions = append(o.DTOOptions, opts...) }
+}
+
+// Decompressor wraps a compressed stream.
+type Decompressor func(io.Reader) (io.Reader, error)
+
+var decompressors sync.Map // string(magic) -> Decompressor
+
+// RegisterDecompressor adds a format detected by WithStreamDecompress or replaces a built-in
+// one. gzip and zstd are built in.
+func RegisterDecompressor(magic []byte, fn Decompressor) { decompressors.Store(string(magic), fn) }
+
+func init() {
+	RegisterDecompressor([]byte{0x1f, 0x8b}, func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) })
+	RegisterDecompressor([]byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.Reader, error) { return newZstdReader(r), nil })
+}
+
+// decompress sniffs br and wraps it when it starts with a registered magic number.
+func decompress(br *bufio.Reader) (io.Reader, error) {
+	head, _ := br.Peek(8)
+	var out io.Reader = br
+	var err error
+	decompressors.Range(func(k, v any) bool {
+		if bytes.HasPrefix(head, []byte(k.(string))) {
+			out, err = v.(Decompressor)(br)
+			return false
+		}
+		return true
+	})
+	return out, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// DTOStreamJSONLParallel is DTOStreamJSONL with a pool of conversion workers. Output keeps
+// the input order, at most InFlight records are held in memory, and the first error, in
+// input order, stops the stream with the record index in its path. After ctx is done the
+// call returns once the pending read completes; WithStreamCloseOnCancel closes r instead.
+func DTOStreamJSONLParallel[T any](ctx context.Context, r io.Reader, w io.Writer, opts ...DTOStreamOption) (DTOStreamStats, error) {
+	o := DTOStreamOptions{Workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	if o.Workers <= 0 {
+		o.Workers = 1
+	}
+	if o.InFlight <= 0 {
+		o.InFlight = 64 * o.Workers
+	}
+	start := time.Now()
+	var stats DTOStreamStats
+	if c, ok := r.(io.Closer); ok && o.CloseOnCancel {
+		stop := context.AfterFunc(ctx, func() { _ = c.Close() })
+		defer stop()
+	}
+	cr := &countingReader{r: r}
+	in := io.Reader(cr)
+	if o.Decompress {
+		var err error
+		if in, err = decompress(bufio.NewReader(cr)); err != nil {
+			return stats, err
+		}
+	}
+
+	type result struct {
+		out []byte
+		err error
+	}
+	type job struct {
+		i    int
+		line []byte
+		res  chan result
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobs := make(chan job)
+	order := make(chan chan result, o.InFlight)
+	var readErr error
+	var wg sync.WaitGroup
+	for n := 0; n < o.Workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				var m any
+				err := json.Unmarshal(j.line, &m)
+				var v T
+				if err == nil {
+					v, err = DTOTo[T](m, o.DTOOptions...)
+				}
+				var out []byte
+				if err == nil {
+					out, err = json.Marshal(v)
+				}
+				if err != nil {
+					err = PathError(indexPath("", j.i), KindString, KindStruct, string(j.line), err)
+				}
+				j.res <- result{append(out, '\n'), err}
+			}
+		}()
+	}
+	go func() {
+		defer close(order)
+		defer close(jobs)
+		s := bufio.NewScanner(in)
+		s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for i := 0; s.Scan(); {
+			stats.Lines++
+			line := bytes.TrimSpace(s.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			j := job{i, append([]byte(nil), line...), make(chan result, 1)}
+			i++
+			select {
+			case order <- j.res:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr = s.Err()
+	}()
+
+	bw := bufio.NewWriter(w)
+	err := func() error {
+		for res := range order {
+			var rs result
+			select {
+			case rs = <-res:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if rs.err != nil {
+				return rs.err
+			}
+			if _, err := bw.Write(rs.out); err != nil {
+				return err
+			}
+			stats.Records++
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return readErr
+	}()
+	cancel()
+	for range order {
+	}
+	wg.Wait()
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	stats.BytesIn = cr.n
+	stats.Duration = time.Since(start)
+	return stats, err
+}
@@ -0,0 +1,142 @@
+package convert
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+)
+
+type streamRec struct {
+	ID   int    `json:"id" csv:"id" db:"id"`
+	Name string `json:"name" csv:"name" db:"name"`
+}
+
+func collectSeq[T any](seq iter.Seq2[T, error]) ([]T, []error) {
+	var vals []T
+	var errs []error
+	for v, err := range seq {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		vals = append(vals, v)
+	}
+	return vals, errs
+}
+
+func TestSeqPolicies(t *testing.T) {
+	const csvData = "id,name\n1,a\nx,b\n3,c\n"
+	const jsonl = "{\"id\":1,\"name\":\"a\"}\n\n{\"id\":\"x\"}\nnot json\n{\"id\":3,\"name\":\"c\"}\n"
+	for name, c := range map[string]struct {
+		seq func(...DTOBatchOption) iter.Seq2[streamRec, error]
+		bad int
+	}{
+		"csv": {func(o ...DTOBatchOption) iter.Seq2[streamRec, error] {
+			return CSVSeq[streamRec](strings.NewReader(csvData), o...)
+		}, 1},
+		"jsonl": {func(o ...DTOBatchOption) iter.Seq2[streamRec, error] {
+			return JSONLSeq[streamRec](strings.NewReader(jsonl), o...)
+		}, 2},
+		"rows": {func(o ...DTOBatchOption) iter.Seq2[streamRec, error] {
+			return RowsSeq[streamRec](sqlTestQuery(t, []string{"id", "name"}, []driver.Value{int64(1), "a"}, []driver.Value{"x", "b"}, []driver.Value{int64(3), "c"}), o...)
+		}, 1},
+	} {
+		vals, errs := collectSeq(c.seq())
+		if len(vals) != 1 || len(errs) != 1 {
+			t.Fatalf("%s stop: %v %v", name, vals, errs)
+		}
+		var d *ErrorDetail
+		if !errors.As(errs[0], &d) || d.Path != "[1]" {
+			t.Fatalf("%s error path: %v", name, errs[0])
+		}
+		vals, errs = collectSeq(c.seq(DTOBatchSkipInvalid()))
+		if len(vals) != 2 || len(errs) != 0 || vals[1].ID != 3 {
+			t.Fatalf("%s skip: %v %v", name, vals, errs)
+		}
+		vals, errs = collectSeq(c.seq(DTOBatchCollectErrors()))
+		if len(vals) != 2 || len(errs) != c.bad {
+			t.Fatalf("%s collect: %v %v", name, vals, errs)
+		}
+		for v := range c.seq(DTOBatchCollectErrors()) {
+			if v.ID != 1 {
+				t.Fatalf("%s early break: %v", name, v)
+			}
+			break
+		}
+	}
+	if vals, errs := collectSeq(CSVSeq[streamRec](strings.NewReader(""))); len(vals)+len(errs) != 0 {
+		t.Fatalf("empty csv: %v %v", vals, errs)
+	}
+	if _, errs := collectSeq(CSVSeq[streamRec](strings.NewReader("id,name\n\"1,a\n"))); len(errs) != 1 {
+		t.Fatalf("malformed csv: %v", errs)
+	}
+}
+
+func TestDTOStreamJSONLParallel(t *testing.T) {
+	var in strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&in, "{\"id\":\"%d\",\"name\":\"n%d\"}\n", i, i)
+		if i%100 == 0 {
+			in.WriteString("\n")
+		}
+	}
+	var want, got bytes.Buffer
+	if err := DTOStreamJSONL[streamRec](context.Background(), strings.NewReader(in.String()), &want); err != nil {
+		t.Fatal(err)
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(in.String()))
+	zw.Close()
+	stats, err := DTOStreamJSONLParallel[streamRec](context.Background(), &gz, &got, WithStreamWorkers(8), WithStreamInFlight(16), WithStreamDecompress())
+	if err != nil || got.String() != want.String() {
+		t.Fatalf("parallel output differs: %v", err)
+	}
+	if stats.Records != 5000 || stats.Lines != 5050 || stats.BytesIn == 0 || stats.RecordsPerSecond() <= 0 {
+		t.Fatalf("stats: %+v", stats)
+	}
+
+	got.Reset()
+	_, err = DTOStreamJSONLParallel[streamRec](context.Background(), strings.NewReader("{\"id\":1}\n{\"id\":2}\n{\"id\":\"x\"}\n{\"id\":4}\n"), &got, WithStreamWorkers(4))
+	var d *ErrorDetail
+	if !errors.As(err, &d) || d.Path != "[2]" || strings.Count(got.String(), "\n") != 2 {
+		t.Fatalf("error stop: %v %q", err, got.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := DTOStreamJSONLParallel[streamRec](ctx, strings.NewReader(in.String()), &got); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled: %v", err)
+	}
+	owned := &closeTracker{Reader: strings.NewReader(in.String())}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := DTOStreamJSONLParallel[streamRec](ctx, owned, &got); !errors.Is(err, context.Canceled) || owned.closed {
+		t.Fatalf("caller's reader closed without opting in: %v %v", err, owned.closed)
+	}
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := DTOStreamJSONLParallel[streamRec](ctx, pr, &got, WithStreamCloseOnCancel()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("blocked read not interrupted: %v", err)
+	}
+	if _, err := DTOStreamJSONLParallel[streamRec](context.Background(), strings.NewReader("\x28\xb5\x2f\xfd..."), &got, WithStreamDecompress()); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("corrupt zstd: %v", err)
+	}
+}
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error { c.closed = true; return nil }
@@ -3,14 +3,15 @@ package convert
 import (
 	"context"
 	"database/sql"
-	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strconv"
@@ -113,7 +114,12 @@ func collectDTOErrors(dst reflect.Value, src any, path string, opt DTOOptions) [
 		fp := joinPath(path, f.primary)
 		if !found {
 			if f.defaultValue != "" {
-				val, found = f.defaultValue, true
+				def, err := expandDefault(f.defaultValue, m, meta, opt)
+				if err != nil {
+					errs = append(errs, PathError(fp, KindString, KindOfReflect(field), f.defaultValue, err))
+					continue
+				}
+				val, found = def, true
 			} else if f.required {
 				errs = append(errs, PathError(fp, KindInvalid, KindOfReflect(field), nil, ErrEmpty))
 			}
@@ -126,16 +132,22 @@ func collectDTOErrors(dst reflect.Value, src any, path string, opt DTOOptions) [
 			continue
 		}
 		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(time.Time{}) {
-			errs = append(errs, collectDTOErrors(field, applyDTOTransforms(val, f.transforms), fp, opt)...)
+			for _, err := range collectDTOErrors(field, applyDTOTransforms(val, f.transforms), fp, opt) {
+				errs = append(errs, markSensitive(err, f.sensitive))
+			}
 			continue
 		}
+		sensitive := f.sensitive
+		if s, ok := val.(string); ok && opt.ResolveSecrets && hasSecretRef(s) {
+			sensitive = true
+		}
 		if err := dtoSet(field, applyDTOTransforms(val, f.transforms), fp, opt); err != nil {
-			errs = append(errs, err)
+			errs = append(errs, markSensitive(err, sensitive))
 			continue
 		}
 		if f.validate {
 			if err := validateReflectField(field, f.structField, fp); err != nil {
-				errs = append(errs, err)
+				errs = append(errs, markSensitive(err, sensitive))
 			}
 		}
 	}
@@ -174,7 +186,7 @@ func DebugError(err error) string {
 	}
 	var d *ErrorDetail
 	if errors.As(err, &d) {
-		return fmt.Sprintf("%s: cannot convert %v(%v) to %v: %v", emptyAs(d.Path, "value"), d.From, d.Value, d.To, d.Cause)
+		return fmt.Sprintf("%s: cannot convert %v(%v) to %v: %v", emptyAs(d.Path, "value"), d.From, redactValue(d.Value, d.Sensitive), d.To, d.Cause)
 	}
 	return err.Error()
 }
@@ -188,6 +200,7 @@ func emptyAs(s, fallback string) string {
 // Description is a field-level documentation row generated from DTO tags.
 type Description struct {
 	Name, GoName, Type, Default, Validate    string
+	Desc                                     string
 	Required, ReadOnly, WriteOnly, Sensitive bool
 	Aliases                                  []string
 }
@@ -209,7 +222,7 @@ func DescribeType(t reflect.Type, opts ...DTOOption) []Description {
 		if len(aliases) > 0 {
 			aliases = aliases[1:]
 		}
-		out = append(out, Description{Name: f.primary, GoName: f.structField.Name, Type: schemaType(f.structField.Type), Default: f.defaultValue, Validate: f.structField.Tag.Get("validate"), Required: f.required, ReadOnly: f.readonly, WriteOnly: f.writeonly, Sensitive: f.sensitive, Aliases: aliases})
+		out = append(out, Description{Name: f.primary, GoName: f.structField.Name, Type: schemaType(f.structField.Type), Default: f.defaultValue, Validate: f.structField.Tag.Get("validate"), Desc: f.structField.Tag.Get("desc"), Required: f.required, ReadOnly: f.readonly, WriteOnly: f.writeonly, Sensitive: f.sensitive, Aliases: aliases})
 	}
 	return out
 }
@@ -245,7 +258,11 @@ func MapTrace[T any](src any, opts ...DTOOption) TraceResult[T] {
 			break
 		}
 	}
-	return TraceResult[T]{Value: out, Steps: steps, Warnings: dtoAnalyzeWarnings(reflect.TypeOf(out), src, o, ""), Err: err}
+	warnings := dtoAnalyzeWarnings(reflect.TypeOf(out), src, o, "")
+	for i := range warnings {
+		warnings[i].Value = redactValue(warnings[i].Value, o.redactPath(warnings[i].Path))
+	}
+	return TraceResult[T]{Value: out, Steps: steps, Warnings: warnings, Err: err}
 }
 func traceValue(t reflect.Type, src any, path string, opt DTOOptions) []TraceStep {
 	t = indirectType(t)
@@ -266,7 +283,7 @@ func traceValue(t reflect.Type, src any, path string, opt DTOOptions) []TraceSte
 			}
 		}
 		fp := joinPath(path, f.primary)
-		step := TraceStep{Path: fp, Source: srcName, Value: v}
+		step := TraceStep{Path: fp, Source: srcName, Value: redactValue(v, f.sensitive || opt.redactPath(fp))}
 		switch {
 		case found && f.readonly:
 			step.Action = "skip-readonly"
@@ -300,7 +317,15 @@ type DryRunReport struct {
 func DryRun[T any](src any, opts ...DTOOption) DryRunReport {
 	tr := MapTrace[T](src, opts...)
 	var out T
-	return DryRunReport{Steps: tr.Steps, Warnings: tr.Warnings, Errors: collectDTOErrors(reflect.ValueOf(&out).Elem(), src, "", dtoOptionsFrom(opts))}
+	o := dtoOptionsFrom(opts)
+	errs := collectDTOErrors(reflect.ValueOf(&out).Elem(), src, "", o)
+	for _, err := range errs {
+		var d *ErrorDetail
+		if errors.As(err, &d) && o.redactPath(d.Path) {
+			d.Value, d.Sensitive = redactValue(d.Value, true), true
+		}
+	}
+	return DryRunReport{Steps: tr.Steps, Warnings: tr.Warnings, Errors: errs}
 }
 
 // Optional represents an API patch field with unset / null / value states.
@@ -419,7 +444,7 @@ func FromMapSource(name string, m map[string]any, tags ...string) BindSource {
 	return BindSource{Name: name, Data: m, Tags: tags}
 }
 func FromQuerySource(v url.Values) BindSource {
-	return BindSource{Name: "query", Data: valuesToMap(v), Tags: []string{"query", "form", "json", "convert"}}
+	return BindSource{Name: "query", Data: queryToMap(v), Tags: []string{"query", "form", "json", "convert"}}
 }
 func FromHeaderSource(h http.Header) BindSource {
 	m := map[string]any{}
@@ -437,17 +462,76 @@ func FromDefaultSource(m map[string]any) BindSource { return BindSource{Name: "d
 // Bind converts multiple sources into T. Later sources overwrite earlier ones.
 func Bind[T any](sources ...BindSource) (T, error) { return BindWithOptions[T](nil, sources...) }
 func BindWithOptions[T any](opts []DTOOption, sources ...BindSource) (T, error) {
+	tags := []string{"convert", "json", "query", "form", "header", "env", "csv", "path", "cookie"}
+	for _, s := range sources {
+		if len(s.Tags) > 0 {
+			tags = append(s.Tags, tags...)
+		}
+	}
+	all := append([]DTOOption{WithDTOTags(uniqueStrings(tags)...), WithDTOFlatten()}, opts...)
+	var z T
+	return DTOTo[T](mergeBindSources(reflect.TypeOf(z), dtoOptionsFrom(all), sources), all...)
+}
+
+// mergeBindSources merges sources in order, later ones winning, then resolves fields tagged
+// from=<source>|... only from the named sources so no other source can override them. A
+// header, path or cookie tag pins a field the same way when a source of that name is present.
+func mergeBindSources(t reflect.Type, opt DTOOptions, sources []BindSource) map[string]any {
 	merged := map[string]any{}
-	tags := []string{"convert", "json", "query", "form", "header", "env", "csv"}
 	for _, s := range sources {
 		for k, v := range s.Data {
 			merged[k] = v
 		}
-		if len(s.Tags) > 0 {
-			tags = append(s.Tags, tags...)
+	}
+	t = indirectType(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return merged
+	}
+	ci := opt.TagPolicy.CaseInsensitive
+fields:
+	for _, f := range dtoMetaFor(t, opt).fields {
+		from := f.from
+		if len(from) == 0 {
+			from = bindTagSources(f.structField.Tag, sources)
+		}
+		if len(from) == 0 {
+			continue
+		}
+		for k := range merged {
+			for _, n := range f.names {
+				if k == n || ci && strings.EqualFold(k, n) {
+					delete(merged, k)
+				}
+			}
+		}
+		for _, name := range from {
+			for _, s := range sources {
+				if s.Name != name {
+					continue
+				}
+				if v, ok, _ := dtoLookup(s.Data, f, ci); ok {
+					merged[f.primary] = v
+					continue fields
+				}
+			}
+		}
+	}
+	return merged
+}
+
+// bindTagSources returns the source a header, path or cookie tag names, if it is among sources.
+func bindTagSources(tag reflect.StructTag, sources []BindSource) []string {
+	for _, name := range []string{"path", "header", "cookie"} {
+		if _, ok := tag.Lookup(name); !ok {
+			continue
+		}
+		for _, s := range sources {
+			if s.Name == name {
+				return []string{name}
+			}
 		}
 	}
-	return DTOTo[T](merged, append([]DTOOption{WithDTOTags(uniqueStrings(tags)...), WithDTOFlatten()}, opts...)...)
+	return nil
 }
 func uniqueStrings(in []string) []string {
 	out := in[:0]
@@ -465,46 +549,40 @@ func uniqueStrings(in []string) []string {
 }
 
 // HTTP helpers. They use net/http only in this optional usability layer.
+
+// BindJSON decodes the body as JSON without body or string size caps; use BindJSONWith to
+// enforce them.
 func BindJSON(r *http.Request, dst any, opts ...DTOOption) error {
-	defer r.Body.Close()
-	var v any
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
-		return err
-	}
-	return DTO(dst, v, append([]DTOOption{WithDTOTags("json", "convert")}, opts...)...)
+	return BindJSONWith(r, dst, withoutBindLimits, WithBindDTOOptions(opts...))
 }
 func BindRequestQuery(r *http.Request, dst any, opts ...DTOOption) error {
-	return DTO(dst, valuesToMap(r.URL.Query()), append([]DTOOption{QueryDTO()}, opts...)...)
+	all := append([]DTOOption{QueryDTO()}, opts...)
+	return DTO(dst, queryValuesMap(r.URL.Query(), reflect.TypeOf(dst), dtoOptionsFrom(all)), all...)
 }
+
+// BindRequestForm binds url-encoded forms; multipart forms go through BindMultipart with
+// DefaultMultipartLimits.
 func BindRequestForm(r *http.Request, dst any, opts ...DTOOption) error {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return BindMultipart(r, dst, DefaultMultipartLimits(), opts...)
+	}
 	if err := r.ParseForm(); err != nil {
 		return err
 	}
-	return DTO(dst, valuesToMap(r.Form), append([]DTOOption{Form()}, opts...)...)
+	all := append([]DTOOption{Form()}, opts...)
+	return DTO(dst, queryValuesMap(r.Form, reflect.TypeOf(dst), dtoOptionsFrom(all)), all...)
 }
 func BindHeaders(r *http.Request, dst any, opts ...DTOOption) error {
 	return DTO(dst, FromHeaderSource(r.Header).Data, append([]DTOOption{Header()}, opts...)...)
 }
+
+// BindRequest merges cookies, the body decoded by Content-Type, query parameters, headers and
+// path values; later sources win, in that order. Fields with a header, path or cookie tag bind
+// only from that source, and a from=path|query tag option restricts a field to the listed
+// sources. Body errors are *RequestError values carrying a 400, 413 or 415 status. The body
+// and string size caps of DefaultHTTPBindOptions apply only through BindRequestWith.
 func BindRequest(r *http.Request, dst any, opts ...DTOOption) error {
-	body := map[string]any{}
-	if r.Body != nil && r.ContentLength != 0 {
-		_ = json.NewDecoder(r.Body).Decode(&body)
-	}
-	_, err := BindWithOptions[map[string]any](nil, FromMapSource("body", body, "json", "convert"), FromQuerySource(r.URL.Query()), FromHeaderSource(r.Header))
-	if err != nil {
-		return err
-	}
-	merged := map[string]any{}
-	for k, v := range body {
-		merged[k] = v
-	}
-	for k, v := range valuesToMap(r.URL.Query()) {
-		merged[k] = v
-	}
-	for k, v := range FromHeaderSource(r.Header).Data {
-		merged[k] = v
-	}
-	return DTO(dst, merged, append([]DTOOption{WithDTOFlatten()}, opts...)...)
+	return BindRequestWith(r, dst, withoutBindLimits, WithBindDTOOptions(opts...))
 }
 
 // Config loader sources.
@@ -514,46 +592,81 @@ type ConfigSource interface {
 type ConfigSourceFunc func() (map[string]any, error)
 
 func (f ConfigSourceFunc) Load() (map[string]any, error) { return f() }
-func EnvSource() ConfigSource {
-	return ConfigSourceFunc(func() (map[string]any, error) {
-		m := map[string]any{}
-		for _, e := range os.Environ() {
-			k, v, _ := strings.Cut(e, "=")
-			m[k] = v
+
+// EnvSource reads the whole process environment. Its keys are never checked by WithConfigStrict.
+func EnvSource() ConfigSource { return envSource{} }
+
+// EnvPrefixSource reads env vars starting with prefix and strips it, so APP_PORT becomes PORT.
+// Unlike EnvSource, every remaining key must match a field under WithConfigStrict.
+func EnvPrefixSource(prefix string) ConfigSource { return envSource{prefix: prefix} }
+
+type envSource struct{ prefix string }
+
+func (s envSource) Load() (map[string]any, error) {
+	m := map[string]any{}
+	for _, e := range os.Environ() {
+		k, v, _ := strings.Cut(e, "=")
+		if s.prefix != "" {
+			var ok bool
+			if k, ok = strings.CutPrefix(k, s.prefix); !ok || k == "" {
+				continue
+			}
 		}
-		return m, nil
-	})
+		m[k] = v
+	}
+	return m, nil
 }
-func MapSource(m map[string]any) ConfigSource {
-	return ConfigSourceFunc(func() (map[string]any, error) { return m, nil })
+func (s envSource) SourceName() string {
+	if s.prefix == "" {
+		return "env"
+	}
+	return "env " + s.prefix + "*"
 }
-func FileSource(path string) ConfigSource {
-	return ConfigSourceFunc(func() (map[string]any, error) {
-		b, err := os.ReadFile(path)
-		if err != nil {
-			return nil, err
-		}
-		var m map[string]any
-		if err := json.Unmarshal(b, &m); err != nil {
-			return nil, err
-		}
-		return m, nil
-	})
+func (s envSource) KeyScope() ConfigKeyScope {
+	if s.prefix == "" {
+		return ConfigKeysIgnore
+	}
+	return ConfigKeysStrict
 }
-func LoadConfig[T any](sources ...ConfigSource) (T, error) {
-	merged := map[string]any{}
-	for _, s := range sources {
-		m, err := s.Load()
-		if err != nil {
-			var z T
-			return z, err
-		}
-		for k, v := range m {
-			merged[k] = v
-		}
+func (s envSource) displayKey(k string) string { return s.prefix + strings.ToUpper(k) }
+
+func MapSource(m map[string]any) ConfigSource { return mapSource(m) }
+
+type mapSource map[string]any
+
+func (s mapSource) Load() (map[string]any, error) { return s, nil }
+func (mapSource) SourceName() string              { return "map" }
+func (mapSource) KeyScope() ConfigKeyScope        { return ConfigKeysStrict }
+
+func FileSource(path string) ConfigSource { return fileSource{path: path} }
+
+type fileSource struct{ path string }
+
+func (s fileSource) Load() (map[string]any, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadProfile reads the sibling file for profile, e.g. config.prod.json next to config.json.
+func (s fileSource) LoadProfile(profile string) (map[string]any, bool, error) {
+	ext := filepath.Ext(s.path)
+	m, err := fileSource{path: strings.TrimSuffix(s.path, ext) + "." + profile + ext}.Load()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
 	}
-	return DTOTo[T](merged, Config(), WithDTOFlatten())
+	return m, err == nil, err
 }
+func (s fileSource) SourceName() string     { return s.path }
+func (fileSource) KeyScope() ConfigKeyScope { return ConfigKeysStrict }
+
+func LoadConfig[T any](sources ...ConfigSource) (T, error) { return LoadConfigWith[T](nil, sources...) }
 func SafeJSON(v any, opts ...DTOOption) string {
 	m, err := Redact(v, opts...)
 	if err != nil {
@@ -575,40 +688,48 @@ type SQLRows interface {
 	Err() error
 }
 
+// FromSQLRow scans one row into T. Struct targets are scanned through a column plan straight
+// into their fields; see FromSQLRows.
 func FromSQLRow[T any](row SQLScanner, columns []string, opts ...DTOOption) (T, error) {
-	vals := make([]any, len(columns))
-	ptrs := make([]any, len(columns))
-	for i := range vals {
-		ptrs[i] = &vals[i]
+	var out T
+	all := append([]DTOOption{WithDTOTags("db", "json", "convert")}, opts...)
+	opt := dtoOptionsFrom(all)
+	plan, ok, err := sqlPlanFor(reflect.TypeFor[T](), columns, opt)
+	if err != nil {
+		return out, err
+	}
+	if ok {
+		return out, newSQLPlanScanner(plan, opt).scan(row, reflect.ValueOf(&out).Elem())
 	}
+	vals, ptrs := sqlAnyDest(len(columns))
 	if err := row.Scan(ptrs...); err != nil {
-		var z T
-		return z, err
+		return out, err
 	}
-	return DTOTo[T](sqlValuesMap(columns, vals), append([]DTOOption{WithDTOTags("db", "json", "convert")}, opts...)...)
+	return DTOTo[T](sqlValuesMap(columns, vals), all...)
 }
+
+// FromSQLRows scans all rows into T. For struct targets the column to field mapping is
+// planned once from db tags and cached per column list; each column is scanned straight into
+// its field, or into a reused temporary for NULL-able basic types and types that need
+// conversion. Columns that match no field by name are matched to nested structs by prefix,
+// so user_id sets User.ID. Use RowsSeq to avoid holding every row.
 func FromSQLRows[T any](rows SQLRows, opts ...DTOOption) ([]T, error) {
-	cols, err := rows.Columns()
-	if err != nil {
-		return nil, err
-	}
 	var out []T
-	for rows.Next() {
-		vals := make([]any, len(cols))
-		ptrs := make([]any, len(cols))
-		for i := range vals {
-			ptrs[i] = &vals[i]
-		}
-		if err := rows.Scan(ptrs...); err != nil {
-			return out, err
-		}
-		v, err := DTOTo[T](sqlValuesMap(cols, vals), append([]DTOOption{WithDTOTags("db", "json", "convert")}, opts...)...)
+	for v, err := range sqlRowsSeq[T](rows, DTOBatchOptions{DTOOptions: opts}) {
 		if err != nil {
 			return out, err
 		}
 		out = append(out, v)
 	}
-	return out, rows.Err()
+	return out, nil
+}
+func sqlAnyDest(n int) ([]any, []any) {
+	vals := make([]any, n)
+	ptrs := make([]any, n)
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	return vals, ptrs
 }
 func sqlValuesMap(cols []string, vals []any) map[string]any {
 	m := map[string]any{}
@@ -635,65 +756,28 @@ func ToSQLArgs(src any, fields ...string) ([]any, error) {
 	}
 	out := make([]any, 0, len(fields))
 	for _, f := range fields {
-		out = append(out, m[f])
+		v := m[f]
+		if dv, ok := v.(driverValuer); ok {
+			if v, err = dv.driverValue(); err != nil {
+				return nil, PathError(f, KindOf(m[f]), KindInvalid, m[f], err)
+			}
+		}
+		out = append(out, v)
 	}
 	return out, nil
 }
 
 var _ = sql.ErrNoRows
 
-// CSV import/export helpers.
+// CSV import/export helpers. See ReadCSVWith and WriteCSVWith for other dialects.
 func ReadCSV[T any](r io.Reader, opts ...DTOOption) ([]T, error) {
-	cr := csv.NewReader(r)
-	headers, err := cr.Read()
-	if err != nil {
-		return nil, err
-	}
-	var out []T
-	for {
-		row, err := cr.Read()
-		if errors.Is(err, io.EOF) {
-			break
-		}
-		if err != nil {
-			return out, err
-		}
-		v, err := FromCSVRow[T](headers, row, opts...)
-		if err != nil {
-			return out, err
-		}
-		out = append(out, v)
-	}
-	return out, nil
+	return ReadCSVWith[T](r, WithCSVDTOOptions(opts...))
 }
+
+// WriteCSV writes rows with a header row as WriteCSVWith does: nested structs as dotted
+// columns, slices joined with commas and maps as JSON.
 func WriteCSV[T any](w io.Writer, rows []T, opts ...DTOOption) error {
-	cw := csv.NewWriter(w)
-	defer cw.Flush()
-	if len(rows) == 0 {
-		return nil
-	}
-	desc := Describe[T](CSV())
-	headers := make([]string, len(desc))
-	for i, d := range desc {
-		headers[i] = d.Name
-	}
-	if err := cw.Write(headers); err != nil {
-		return err
-	}
-	for _, row := range rows {
-		m, err := StructToMap(row, append([]DTOOption{CSV()}, opts...)...)
-		if err != nil {
-			return err
-		}
-		rec := make([]string, len(headers))
-		for i, h := range headers {
-			rec[i] = ToDebugString(m[h])
-		}
-		if err := cw.Write(rec); err != nil {
-			return err
-		}
-	}
-	return cw.Error()
+	return WriteCSVWith(w, rows, WithCSVDTOOptions(opts...))
 }
 
 // Collection helpers.
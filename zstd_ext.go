@@ -0,0 +1,842 @@
+package convert
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math/bits"
+)
+
+// zstd decoding (RFC 8878) for WithStreamDecompress. Frames without a dictionary are
+// supported; windows are capped at 128 MiB, the reference decoder's default limit.
+
+const (
+	zstdMaxWindow   = 1 << 27
+	zstdMaxBlock    = 128 << 10
+	zstdFrameMagic  = 0xfd2fb528
+	zstdSkipMagic   = 0x184d2a50 // low four bits vary
+	zstdSkipMask    = 0xfffffff0
+	zstdMaxLLCode   = 35
+	zstdMaxMLCode   = 52
+	zstdMaxOFCode   = 31
+	zstdHuffMaxBits = 11
+)
+
+func zstdError(msg string) error { return errf("zstd: %s: %w", msg, ErrInvalid) }
+
+type zstdReader struct {
+	br  *bufio.Reader
+	out []byte // decoded bytes not yet returned by Read
+	err error
+
+	inFrame  bool
+	window   int
+	checksum bool
+	size     int64 // frame content size, -1 when unknown
+	written  int64
+	hist     []byte // decoded frame content, trimmed to the window between blocks
+	hash     zstdXXH64
+
+	rep        [3]int
+	huff       *zstdHuffTable
+	ll, of, ml *zstdFSETable
+	scratch    []byte
+}
+
+func newZstdReader(r io.Reader) io.Reader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &zstdReader{br: br}
+}
+
+func (z *zstdReader) Read(p []byte) (int, error) {
+	for len(z.out) == 0 && z.err == nil {
+		z.err = z.step()
+	}
+	n := copy(p, z.out)
+	z.out = z.out[n:]
+	if len(z.out) == 0 && n == 0 {
+		return 0, z.err
+	}
+	return n, nil
+}
+
+// step decodes the next block, starting a new frame when needed.
+func (z *zstdReader) step() error {
+	if !z.inFrame {
+		return z.frameHeader()
+	}
+	var h [3]byte
+	if _, err := io.ReadFull(z.br, h[:]); err != nil {
+		return zstdUnexpected(err)
+	}
+	v := uint32(h[0]) | uint32(h[1])<<8 | uint32(h[2])<<16
+	last, typ, size := v&1 == 1, (v>>1)&3, int(v>>3)
+	if size > min(z.window, zstdMaxBlock) {
+		return zstdError("block too large")
+	}
+	if len(z.hist) > z.window && len(z.hist)+zstdMaxBlock > cap(z.hist) {
+		n := copy(z.hist, z.hist[len(z.hist)-z.window:])
+		z.hist = z.hist[:n]
+	}
+	start := len(z.hist)
+	switch typ {
+	case 0:
+		z.hist = append(z.hist, make([]byte, size)...)
+		if _, err := io.ReadFull(z.br, z.hist[start:]); err != nil {
+			return zstdUnexpected(err)
+		}
+	case 1:
+		b, err := z.br.ReadByte()
+		if err != nil {
+			return zstdUnexpected(err)
+		}
+		for range size {
+			z.hist = append(z.hist, b)
+		}
+	case 2:
+		if cap(z.scratch) < size {
+			z.scratch = make([]byte, size)
+		}
+		data := z.scratch[:size]
+		if _, err := io.ReadFull(z.br, data); err != nil {
+			return zstdUnexpected(err)
+		}
+		if err := z.compressedBlock(data, start); err != nil {
+			return err
+		}
+	default:
+		return zstdError("reserved block type")
+	}
+	z.out = z.hist[start:]
+	z.written += int64(len(z.out))
+	if z.checksum {
+		z.hash.Write(z.out)
+	}
+	if !last {
+		return nil
+	}
+	z.inFrame = false
+	if z.size >= 0 && z.written != z.size {
+		return zstdError("content size mismatch")
+	}
+	if z.checksum {
+		var c [4]byte
+		if _, err := io.ReadFull(z.br, c[:]); err != nil {
+			return zstdUnexpected(err)
+		}
+		if binary.LittleEndian.Uint32(c[:]) != uint32(z.hash.Sum64()) {
+			return zstdError("checksum mismatch")
+		}
+	}
+	return nil
+}
+
+func zstdUnexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// frameHeader reads the next frame header, skipping skippable frames.
+func (z *zstdReader) frameHeader() error {
+	var m [4]byte
+	for {
+		if _, err := io.ReadFull(z.br, m[:]); err != nil {
+			return err
+		}
+		magic := binary.LittleEndian.Uint32(m[:])
+		if magic == zstdFrameMagic {
+			break
+		}
+		if magic&zstdSkipMask != zstdSkipMagic {
+			return zstdError("bad magic number")
+		}
+		if _, err := io.ReadFull(z.br, m[:]); err != nil {
+			return zstdUnexpected(err)
+		}
+		if _, err := z.br.Discard(int(binary.LittleEndian.Uint32(m[:]))); err != nil {
+			return zstdUnexpected(err)
+		}
+	}
+	fhd, err := z.br.ReadByte()
+	if err != nil {
+		return zstdUnexpected(err)
+	}
+	if fhd&0x08 != 0 {
+		return zstdError("reserved frame header bit")
+	}
+	single := fhd&0x20 != 0
+	window := 0
+	if !single {
+		wd, err := z.br.ReadByte()
+		if err != nil {
+			return zstdUnexpected(err)
+		}
+		exp := 10 + int(wd>>3)
+		if exp > 31 {
+			return errf("zstd: window exceeds %d bytes: %w", zstdMaxWindow, ErrTooLarge)
+		}
+		base := 1 << exp
+		window = base + base/8*int(wd&7)
+	}
+	var field [8]byte
+	if n := [4]int{0, 1, 2, 4}[fhd&3]; n > 0 {
+		if _, err := io.ReadFull(z.br, field[:n]); err != nil {
+			return zstdUnexpected(err)
+		}
+		if binary.LittleEndian.Uint32(field[:4]) != 0 {
+			return errf("zstd: dictionaries are not supported: %w", ErrUnsupported)
+		}
+	}
+	z.size = -1
+	n := [4]int{0, 2, 4, 8}[fhd>>6]
+	if n == 0 && single {
+		n = 1
+	}
+	if n > 0 {
+		field = [8]byte{}
+		if _, err := io.ReadFull(z.br, field[:n]); err != nil {
+			return zstdUnexpected(err)
+		}
+		z.size = int64(binary.LittleEndian.Uint64(field[:]))
+		if n == 2 {
+			z.size += 256
+		}
+		if z.size < 0 {
+			return zstdError("bad content size")
+		}
+	}
+	if single {
+		window = int(min(z.size, zstdMaxWindow+1))
+	}
+	if window > zstdMaxWindow {
+		return errf("zstd: window exceeds %d bytes: %w", zstdMaxWindow, ErrTooLarge)
+	}
+	*z = zstdReader{br: z.br, scratch: z.scratch, hist: z.hist[:0], inFrame: true, window: window, checksum: fhd&0x04 != 0, size: z.size, rep: [3]int{1, 4, 8}}
+	return nil
+}
+
+// compressedBlock decodes a compressed block whose output starts at hist[start].
+func (z *zstdReader) compressedBlock(data []byte, start int) error {
+	lits, n, err := z.literals(data)
+	if err != nil {
+		return err
+	}
+	data = data[n:]
+	if len(data) == 0 {
+		return zstdError("missing sequences section")
+	}
+	nseq := int(data[0])
+	switch {
+	case nseq == 0:
+		data = data[1:]
+	case nseq < 128:
+		data = data[1:]
+	case nseq < 255:
+		if len(data) < 2 {
+			return zstdError("truncated sequences header")
+		}
+		nseq, data = (nseq-128)<<8+int(data[1]), data[2:]
+	default:
+		if len(data) < 3 {
+			return zstdError("truncated sequences header")
+		}
+		nseq, data = int(data[1])+int(data[2])<<8+0x7f00, data[3:]
+	}
+	if nseq == 0 {
+		if len(data) != 0 {
+			return zstdError("trailing block data")
+		}
+		z.hist = append(z.hist, lits...)
+		return z.checkBlock(start)
+	}
+	if len(data) == 0 {
+		return zstdError("missing compression modes")
+	}
+	modes := data[0]
+	if modes&3 != 0 {
+		return zstdError("reserved compression mode bits")
+	}
+	data = data[1:]
+	for _, t := range []struct {
+		mode   byte
+		table  **zstdFSETable
+		def    *zstdFSETable
+		maxLog int
+		maxSym int
+	}{
+		{modes >> 6, &z.ll, zstdDefaultLL, 9, zstdMaxLLCode},
+		{modes >> 4 & 3, &z.of, zstdDefaultOF, 8, zstdMaxOFCode},
+		{modes >> 2 & 3, &z.ml, zstdDefaultML, 9, zstdMaxMLCode},
+	} {
+		switch t.mode {
+		case 0:
+			*t.table = t.def
+		case 1:
+			if len(data) == 0 || int(data[0]) > t.maxSym {
+				return zstdError("bad RLE sequence code")
+			}
+			*t.table = &zstdFSETable{entries: []zstdFSEEntry{{symbol: data[0]}}}
+			data = data[1:]
+		case 2:
+			norm, n, err := zstdReadNorm(data, t.maxSym, t.maxLog)
+			if err != nil {
+				return err
+			}
+			*t.table, data = zstdBuildFSE(norm), data[n:]
+		case 3:
+			if *t.table == nil {
+				return zstdError("repeat mode without a previous table")
+			}
+		}
+	}
+	return z.sequences(data, nseq, lits, start)
+}
+
+func (z *zstdReader) checkBlock(start int) error {
+	if len(z.hist)-start > zstdMaxBlock {
+		return zstdError("block output too large")
+	}
+	return nil
+}
+
+var (
+	zstdLLBase = [...]uint32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 18, 20, 22, 24, 28, 32, 40, 48, 64, 128, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768, 65536}
+	zstdLLBits = [...]uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 3, 3, 4, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	zstdMLBase = [...]uint32{3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34,
+		35, 37, 39, 41, 43, 47, 51, 59, 67, 83, 99, 131, 259, 515, 1027, 2051, 4099, 8195, 16387, 32771, 65539}
+	zstdMLBits = [...]uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		1, 1, 1, 1, 2, 2, 3, 3, 4, 4, 5, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+	zstdDefaultLL = zstdBuildFSE(zstdNorm{log: 6, counts: []int16{4, 3, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 3, 2, 1, 1, 1, 1, 1, -1, -1, -1, -1}})
+	zstdDefaultML = zstdBuildFSE(zstdNorm{log: 6, counts: []int16{1, 4, 3, 2, 2, 2, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, -1, -1, -1, -1, -1, -1, -1}})
+	zstdDefaultOF = zstdBuildFSE(zstdNorm{log: 5, counts: []int16{1, 1, 1, 1, 1, 1, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, -1, -1, -1, -1, -1}})
+)
+
+// sequences executes the block's sequences against lits and the window history.
+func (z *zstdReader) sequences(data []byte, nseq int, lits []byte, start int) error {
+	br, err := newZstdBackward(data)
+	if err != nil {
+		return err
+	}
+	ll, of, ml := z.ll.init(br), z.of.init(br), z.ml.init(br)
+	for i := range nseq {
+		ofCode, llCode, mlCode := z.of.entries[of].symbol, z.ll.entries[ll].symbol, z.ml.entries[ml].symbol
+		if ofCode > zstdMaxOFCode || llCode > zstdMaxLLCode || mlCode > zstdMaxMLCode {
+			return zstdError("bad sequence code")
+		}
+		ofv := int(1)<<ofCode + int(br.read(ofCode))
+		mlen := int(zstdMLBase[mlCode]) + int(br.read(zstdMLBits[mlCode]))
+		llen := int(zstdLLBase[llCode]) + int(br.read(zstdLLBits[llCode]))
+		var off int
+		if ofv > 3 {
+			off = ofv - 3
+			z.rep = [3]int{off, z.rep[0], z.rep[1]}
+		} else {
+			idx := ofv - 1
+			if llen == 0 {
+				idx++
+			}
+			switch idx {
+			case 0:
+				off = z.rep[0]
+			case 1:
+				off = z.rep[1]
+				z.rep[0], z.rep[1] = off, z.rep[0]
+			case 2:
+				off = z.rep[2]
+				z.rep = [3]int{off, z.rep[0], z.rep[1]}
+			default:
+				off = z.rep[0] - 1
+				if off == 0 {
+					return zstdError("zero offset")
+				}
+				z.rep = [3]int{off, z.rep[0], z.rep[1]}
+			}
+		}
+		if llen > len(lits) {
+			return zstdError("literal length exceeds literals")
+		}
+		z.hist = append(z.hist, lits[:llen]...)
+		lits = lits[llen:]
+		if off > len(z.hist) || off > z.window {
+			return zstdError("offset beyond window")
+		}
+		if len(z.hist)-start+mlen > zstdMaxBlock {
+			return zstdError("block output too large")
+		}
+		from := len(z.hist) - off
+		if off >= mlen {
+			z.hist = append(z.hist, z.hist[from:from+mlen]...)
+		} else {
+			for j := range mlen {
+				z.hist = append(z.hist, z.hist[from+j])
+			}
+		}
+		if i < nseq-1 {
+			ll = z.ll.next(ll, br)
+			ml = z.ml.next(ml, br)
+			of = z.of.next(of, br)
+		}
+	}
+	if br.pos != 0 {
+		return zstdError("sequence bitstream not fully consumed")
+	}
+	z.hist = append(z.hist, lits...)
+	return z.checkBlock(start)
+}
+
+// literals decodes the literals section of a compressed block, returning the literals and
+// the section's size in bytes.
+func (z *zstdReader) literals(data []byte) ([]byte, int, error) {
+	if len(data) == 0 {
+		return nil, 0, zstdError("missing literals section")
+	}
+	typ, format := data[0]&3, data[0]>>2&3
+	if typ < 2 {
+		hlen, shift := [4]int{1, 2, 1, 3}[format], [4]uint{3, 4, 3, 4}[format]
+		if len(data) < hlen {
+			return nil, 0, zstdError("truncated literals header")
+		}
+		size := int(zstdLE(data[:hlen]) >> shift)
+		if size > zstdMaxBlock {
+			return nil, 0, zstdError("literals too large")
+		}
+		if typ == 0 {
+			if len(data) < hlen+size {
+				return nil, 0, zstdError("truncated literals")
+			}
+			return data[hlen : hlen+size], hlen + size, nil
+		}
+		if len(data) < hlen+1 {
+			return nil, 0, zstdError("truncated literals")
+		}
+		out := make([]byte, size)
+		for i := range out {
+			out[i] = data[hlen]
+		}
+		return out, hlen + 1, nil
+	}
+	hlen, width := [4]int{3, 3, 4, 5}[format], [4]uint{10, 10, 14, 18}[format]
+	if len(data) < hlen {
+		return nil, 0, zstdError("truncated literals header")
+	}
+	v := zstdLE(data[:hlen]) >> 4
+	regen, csize := int(v&(1<<width-1)), int(v>>width&(1<<width-1))
+	if regen > zstdMaxBlock || len(data) < hlen+csize {
+		return nil, 0, zstdError("bad literals size")
+	}
+	src := data[hlen : hlen+csize]
+	if typ == 2 {
+		t, n, err := zstdReadHuffman(src)
+		if err != nil {
+			return nil, 0, err
+		}
+		z.huff, src = t, src[n:]
+	} else if z.huff == nil {
+		return nil, 0, zstdError("treeless literals without a previous table")
+	}
+	out := make([]byte, regen)
+	if format == 0 {
+		if err := z.huff.decode(src, out); err != nil {
+			return nil, 0, err
+		}
+		return out, hlen + csize, nil
+	}
+	if len(src) < 6 {
+		return nil, 0, zstdError("truncated jump table")
+	}
+	s1, s2, s3 := int(binary.LittleEndian.Uint16(src)), int(binary.LittleEndian.Uint16(src[2:])), int(binary.LittleEndian.Uint16(src[4:]))
+	src = src[6:]
+	if s1+s2+s3 > len(src) {
+		return nil, 0, zstdError("bad jump table")
+	}
+	per := (regen + 3) / 4
+	if 3*per > regen {
+		return nil, 0, zstdError("bad literals size")
+	}
+	streams := [4][]byte{src[:s1], src[s1 : s1+s2], src[s1+s2 : s1+s2+s3], src[s1+s2+s3:]}
+	for i, s := range streams {
+		end := min((i+1)*per, regen)
+		if i == 3 {
+			end = regen
+		}
+		if err := z.huff.decode(s, out[i*per:end]); err != nil {
+			return nil, 0, err
+		}
+	}
+	return out, hlen + csize, nil
+}
+
+func zstdLE(b []byte) uint64 {
+	var v uint64
+	for i, c := range b {
+		v |= uint64(c) << (8 * i)
+	}
+	return v
+}
+
+// zstdBackward reads a bitstream from its end, as FSE and Huffman streams are written.
+type zstdBackward struct {
+	b   []byte
+	pos int // unread bits; negative once the stream is overread
+}
+
+func newZstdBackward(b []byte) (*zstdBackward, error) {
+	if len(b) == 0 || b[len(b)-1] == 0 {
+		return nil, zstdError("bad bitstream padding")
+	}
+	return &zstdBackward{b: b, pos: (len(b)-1)*8 + bits.Len8(b[len(b)-1]) - 1}, nil
+}
+
+// peek returns the next n bits without consuming them, padding with zeros past the start.
+func (r *zstdBackward) peek(n uint8) uint64 {
+	if n == 0 {
+		return 0
+	}
+	start := r.pos - int(n)
+	if start >= 0 {
+		return r.load(start) & (1<<n - 1)
+	}
+	if have := int(n) + start; have > 0 {
+		return (r.load(0) & (1<<have - 1)) << -start
+	}
+	return 0
+}
+func (r *zstdBackward) load(bit int) uint64 {
+	var buf [8]byte
+	copy(buf[:], r.b[bit/8:])
+	return binary.LittleEndian.Uint64(buf[:]) >> (bit % 8)
+}
+func (r *zstdBackward) read(n uint8) uint64 {
+	v := r.peek(n)
+	r.pos -= int(n)
+	return v
+}
+
+// zstdNorm is a normalized FSE distribution; -1 marks a "less than one" probability.
+type zstdNorm struct {
+	log    int
+	counts []int16
+}
+
+// zstdReadNorm reads an FSE table description from the front of data.
+func zstdReadNorm(data []byte, maxSym, maxLog int) (zstdNorm, int, error) {
+	var pos int // bits consumed
+	read := func(n int) uint32 {
+		var buf [4]byte
+		copy(buf[:], data[min(pos/8, len(data)):])
+		return binary.LittleEndian.Uint32(buf[:]) >> (pos % 8) & (1<<n - 1)
+	}
+	if len(data) == 0 {
+		return zstdNorm{}, 0, zstdError("truncated FSE table")
+	}
+	norm := zstdNorm{log: int(data[0]&15) + 5}
+	if norm.log > maxLog {
+		return zstdNorm{}, 0, zstdError("FSE accuracy too high")
+	}
+	pos = 4
+	remaining := 1<<norm.log + 1
+	threshold := 1 << norm.log
+	nbits := norm.log + 1
+	zero := false
+	for remaining > 1 {
+		if zero {
+			for {
+				r := int(read(2))
+				pos += 2
+				for range r {
+					norm.counts = append(norm.counts, 0)
+				}
+				if r != 3 {
+					break
+				}
+				if len(norm.counts) > maxSym+1 {
+					return zstdNorm{}, 0, zstdError("too many FSE symbols")
+				}
+			}
+		}
+		if len(norm.counts) > maxSym || pos > len(data)*8 {
+			return zstdNorm{}, 0, zstdError("bad FSE table")
+		}
+		max := 2*threshold - 1 - remaining
+		v := int(read(nbits))
+		if v&(threshold-1) < max {
+			v &= threshold - 1
+			pos += nbits - 1
+		} else {
+			if v >= threshold {
+				v -= max
+			}
+			pos += nbits
+		}
+		count := v - 1
+		if count < 0 {
+			remaining--
+		} else {
+			remaining -= count
+		}
+		norm.counts = append(norm.counts, int16(count))
+		zero = count == 0
+		for remaining < threshold && threshold > 1 {
+			nbits--
+			threshold >>= 1
+		}
+	}
+	if remaining != 1 || pos > len(data)*8 {
+		return zstdNorm{}, 0, zstdError("bad FSE table")
+	}
+	return norm, (pos + 7) / 8, nil
+}
+
+type zstdFSEEntry struct {
+	symbol uint8
+	nbits  uint8
+	base   uint16
+}
+type zstdFSETable struct {
+	log     uint8
+	entries []zstdFSEEntry
+}
+
+func zstdBuildFSE(norm zstdNorm) *zstdFSETable {
+	size := 1 << norm.log
+	t := &zstdFSETable{log: uint8(norm.log), entries: make([]zstdFSEEntry, size)}
+	next := make([]int, len(norm.counts))
+	high := size - 1
+	for s, c := range norm.counts {
+		if c == -1 {
+			t.entries[high].symbol = uint8(s)
+			high--
+			next[s] = 1
+		} else {
+			next[s] = int(c)
+		}
+	}
+	step, mask, pos := size>>1+size>>3+3, size-1, 0
+	for s, c := range norm.counts {
+		for range max(int(c), 0) {
+			t.entries[pos].symbol = uint8(s)
+			for pos = (pos + step) & mask; pos > high; pos = (pos + step) & mask {
+			}
+		}
+	}
+	for i := range t.entries {
+		e := &t.entries[i]
+		n := next[e.symbol]
+		next[e.symbol]++
+		e.nbits = uint8(norm.log - (bits.Len(uint(n)) - 1))
+		e.base = uint16(n<<e.nbits - size)
+	}
+	return t
+}
+
+func (t *zstdFSETable) init(br *zstdBackward) int { return int(br.read(t.log)) }
+func (t *zstdFSETable) next(state int, br *zstdBackward) int {
+	e := t.entries[state]
+	return int(e.base) + int(br.read(e.nbits))
+}
+
+type zstdHuffTable struct {
+	maxBits uint8
+	entries []struct{ symbol, nbits uint8 }
+}
+
+// zstdReadHuffman reads a Huffman tree description, returning the table and its size.
+func zstdReadHuffman(data []byte) (*zstdHuffTable, int, error) {
+	if len(data) == 0 {
+		return nil, 0, zstdError("truncated Huffman tree")
+	}
+	var weights []byte
+	n := 1
+	if h := int(data[0]); h >= 128 {
+		count := h - 127
+		n += (count + 1) / 2
+		if len(data) < n {
+			return nil, 0, zstdError("truncated Huffman weights")
+		}
+		for i := range count {
+			w := data[1+i/2]
+			if i%2 == 0 {
+				w >>= 4
+			}
+			weights = append(weights, w&15)
+		}
+	} else {
+		n += h
+		if len(data) < n {
+			return nil, 0, zstdError("truncated Huffman weights")
+		}
+		var err error
+		if weights, err = zstdHuffWeights(data[1:n]); err != nil {
+			return nil, 0, err
+		}
+	}
+	if len(weights) > 255 {
+		return nil, 0, zstdError("too many Huffman weights")
+	}
+	var total uint32
+	for _, w := range weights {
+		if w > zstdHuffMaxBits {
+			return nil, 0, zstdError("bad Huffman weight")
+		}
+		if w > 0 {
+			total += 1 << (w - 1)
+		}
+	}
+	if total == 0 {
+		return nil, 0, zstdError("empty Huffman tree")
+	}
+	maxBits := bits.Len32(total)
+	rest := uint32(1)<<maxBits - total
+	if maxBits > zstdHuffMaxBits || rest&(rest-1) != 0 {
+		return nil, 0, zstdError("bad Huffman weights")
+	}
+	weights = append(weights, byte(bits.Len32(rest)))
+	t := &zstdHuffTable{maxBits: uint8(maxBits), entries: make([]struct{ symbol, nbits uint8 }, 1<<maxBits)}
+	pos := 0
+	for w := byte(1); w <= byte(maxBits); w++ {
+		for s, sw := range weights {
+			if sw != w {
+				continue
+			}
+			for range 1 << (w - 1) {
+				t.entries[pos].symbol, t.entries[pos].nbits = uint8(s), uint8(maxBits)+1-w
+				pos++
+			}
+		}
+	}
+	return t, n, nil
+}
+
+// zstdHuffWeights decodes FSE-compressed Huffman weights with two interleaved states.
+func zstdHuffWeights(data []byte) ([]byte, error) {
+	norm, n, err := zstdReadNorm(data, 255, 6)
+	if err != nil {
+		return nil, err
+	}
+	t := zstdBuildFSE(norm)
+	br, err := newZstdBackward(data[n:])
+	if err != nil {
+		return nil, err
+	}
+	s1, s2 := t.init(br), t.init(br)
+	var out []byte
+	for len(out) < 255 {
+		out = append(out, t.entries[s1].symbol)
+		if s1 = t.next(s1, br); br.pos < 0 {
+			return append(out, t.entries[s2].symbol), nil
+		}
+		out = append(out, t.entries[s2].symbol)
+		if s2 = t.next(s2, br); br.pos < 0 {
+			return append(out, t.entries[s1].symbol), nil
+		}
+	}
+	return nil, zstdError("too many Huffman weights")
+}
+
+func (t *zstdHuffTable) decode(src, out []byte) error {
+	br, err := newZstdBackward(src)
+	if err != nil {
+		return err
+	}
+	for i := range out {
+		e := t.entries[br.peek(t.maxBits)]
+		out[i] = e.symbol
+		br.pos -= int(e.nbits)
+	}
+	if br.pos != 0 {
+		return zstdError("Huffman stream not fully consumed")
+	}
+	return nil
+}
+
+// zstdXXH64 is the streaming XXH64 (seed 0) used for frame checksums.
+type zstdXXH64 struct {
+	v     [4]uint64
+	buf   [32]byte
+	n     int
+	total uint64
+	init  bool
+}
+
+const (
+	xxhP1 uint64 = 11400714785074694791
+	xxhP2 uint64 = 14029467366897019727
+	xxhP3 uint64 = 1609587929392839161
+	xxhP4 uint64 = 9650029242287828579
+	xxhP5 uint64 = 2870177450012600261
+)
+
+func xxhRound(acc, in uint64) uint64 {
+	return bits.RotateLeft64(acc+in*xxhP2, 31) * xxhP1
+}
+func xxhMerge(acc, v uint64) uint64 {
+	return (acc^xxhRound(0, v))*xxhP1 + xxhP4
+}
+
+func (h *zstdXXH64) Write(p []byte) {
+	if !h.init {
+		p1 := xxhP1
+		h.v = [4]uint64{p1 + xxhP2, xxhP2, 0, -p1}
+		h.init = true
+	}
+	h.total += uint64(len(p))
+	if h.n > 0 {
+		c := copy(h.buf[h.n:], p)
+		h.n += c
+		p = p[c:]
+		if h.n < 32 {
+			return
+		}
+		h.blocks(h.buf[:])
+		h.n = 0
+	}
+	full := len(p) &^ 31
+	h.blocks(p[:full])
+	h.n = copy(h.buf[:], p[full:])
+}
+func (h *zstdXXH64) blocks(p []byte) {
+	for ; len(p) >= 32; p = p[32:] {
+		for i := range h.v {
+			h.v[i] = xxhRound(h.v[i], binary.LittleEndian.Uint64(p[8*i:]))
+		}
+	}
+}
+func (h *zstdXXH64) Sum64() uint64 {
+	var acc uint64
+	if h.total >= 32 {
+		v := h.v
+		acc = bits.RotateLeft64(v[0], 1) + bits.RotateLeft64(v[1], 7) + bits.RotateLeft64(v[2], 12) + bits.RotateLeft64(v[3], 18)
+		for _, x := range v {
+			acc = xxhMerge(acc, x)
+		}
+	} else {
+		acc = xxhP5
+	}
+	acc += h.total
+	p := h.buf[:h.n]
+	for ; len(p) >= 8; p = p[8:] {
+		acc ^= xxhRound(0, binary.LittleEndian.Uint64(p))
+		acc = bits.RotateLeft64(acc, 27)*xxhP1 + xxhP4
+	}
+	if len(p) >= 4 {
+		acc ^= uint64(binary.LittleEndian.Uint32(p)) * xxhP1
+		acc = bits.RotateLeft64(acc, 23)*xxhP2 + xxhP3
+		p = p[4:]
+	}
+	for _, c := range p {
+		acc ^= uint64(c) * xxhP5
+		acc = bits.RotateLeft64(acc, 11) * xxhP1
+	}
+	acc ^= acc >> 33
+	acc *= xxhP2
+	acc ^= acc >> 29
+	acc *= xxhP3
+	acc ^= acc >> 32
+	return acc
+}
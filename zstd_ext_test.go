@@ -0,0 +1,73 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// zstdRecords is the content of testdata/records*.jsonl.zst, written by the zstd CLI at
+// levels 19 (with checksum) and 1 (without).
+func zstdRecords() string {
+	var b strings.Builder
+	for i := range 150 {
+		fmt.Fprintf(&b, "{\"id\":%d,\"name\":\"user-%d\",\"score\":%d}\n", i, i%13, i*7919%1000)
+	}
+	return b.String()
+}
+
+func TestZstdReader(t *testing.T) {
+	want := zstdRecords()
+	var frames []byte
+	for _, name := range []string{"testdata/records.jsonl.zst", "testdata/records-fast.jsonl.zst"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(newZstdReader(bytes.NewReader(data)))
+		if err != nil || string(got) != want {
+			t.Fatalf("%s: %d bytes, %v", name, len(got), err)
+		}
+		frames = append(frames, data...)
+	}
+	skip := []byte{0x50, 0x2a, 0x4d, 0x18, 3, 0, 0, 0, 'a', 'b', 'c'}
+	rle := []byte{0x28, 0xb5, 0x2f, 0xfd, 0x20, 5, 0x2b, 0, 0, 'z'}
+	got, err := io.ReadAll(newZstdReader(bytes.NewReader(append(append(skip, frames...), rle...))))
+	if err != nil || string(got) != want+want+"zzzzz" {
+		t.Fatalf("concatenated frames: %d bytes, %v", len(got), err)
+	}
+
+	data, _ := os.ReadFile("testdata/records.jsonl.zst")
+	bad := append([]byte(nil), data...)
+	bad[len(bad)-1] ^= 1
+	if _, err := io.ReadAll(newZstdReader(bytes.NewReader(bad))); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("checksum: %v", err)
+	}
+	if _, err := io.ReadAll(newZstdReader(bytes.NewReader(data[:len(data)/2]))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("truncated: %v", err)
+	}
+	for _, c := range []struct {
+		in   []byte
+		want error
+	}{
+		{[]byte{0x28, 0xb5, 0x2f, 0xfd, 0x01, 0x00, 0x05}, ErrUnsupported}, // dictionary ID 5
+		{[]byte{0x28, 0xb5, 0x2f, 0xfd, 0x00, 0xf8}, ErrTooLarge},          // 2 TiB window
+		{[]byte{0x28, 0xb5, 0x2f, 0xfd, 0x08}, ErrInvalid},                 // reserved bit
+		{[]byte("not zstd"), ErrInvalid},
+	} {
+		if _, err := io.ReadAll(newZstdReader(bytes.NewReader(c.in))); !errors.Is(err, c.want) {
+			t.Fatalf("% x: %v", c.in, err)
+		}
+	}
+
+	var out bytes.Buffer
+	stats, err := DTOStreamJSONLParallel[streamRec](context.Background(), bytes.NewReader(data), &out, WithStreamDecompress())
+	if err != nil || stats.Records != 150 || !strings.Contains(out.String(), `"name":"user-12"`) {
+		t.Fatalf("stream: %+v %v", stats, err)
+	}
+}